@@ -0,0 +1,209 @@
+package restful
+
+// Copyright 2024 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ApiVersionNegotiation is used to select one of several WebServices that share the same root path
+// but differ in ApiVersion. The requested version is taken from (in order of precedence)
+// the Accept header, the Header, the QueryParameter or else the Default.
+// Version values are compared without a leading "v", so "v2" and "2" are equal.
+// A Route that produces application/json is selected for e.g. Accept: application/vnd.acme.v2+json ;
+// its entity is written as application/json unless the Route also produces the vendor media type.
+//
+//	container.ApiVersionNegotiation(restful.ApiVersionNegotiation{
+//		MediaTypeVendor: "vnd.acme", // Accept: application/vnd.acme.v2+json
+//		Header:          "Api-Version",
+//		Default:         "1",
+//	})
+type ApiVersionNegotiation struct {
+	// MediaTypeVendor is the vendor prefix of media types in the Accept header that carry a version,
+	// e.g. "vnd.acme" for application/vnd.acme.v2+json. If set, the media type parameter "version"
+	// (e.g. application/json;version=2) is recognized as well.
+	MediaTypeVendor string
+
+	// Header is the name of the request header with the version, e.g. Api-Version. Optional.
+	Header string
+
+	// QueryParameter is the name of the query parameter with the version, e.g. api-version. Optional.
+	QueryParameter string
+
+	// Default is the version to use if the request does not specify one.
+	// If empty then the first added WebService is used.
+	Default string
+}
+
+// ApiVersionNegotiation enables the selection of WebServices by their ApiVersion.
+// Configure it before adding WebServices that share a root path ; without it, Add rejects such WebServices.
+// The QueryParameter and Header are accepted by StrictQueryParameters and StrictHeaderParameters without being documented.
+// Requests for a version that is not available for the requested root path are answered
+// with 406 (Not Acceptable) if requested using the Accept header, 400 (Bad Request) otherwise.
+// The response lists the available versions.
+func (c *Container) ApiVersionNegotiation(negotiation ApiVersionNegotiation) {
+	c.apiVersionNegotiation = &negotiation
+}
+
+// requestedVersion returns the version requested and whether it was specified using the Accept header.
+func (n ApiVersionNegotiation) requestedVersion(httpRequest *http.Request) (version string, fromAccept bool) {
+	if len(n.MediaTypeVendor) > 0 {
+		for _, each := range strings.Split(httpRequest.Header.Get(HEADER_Accept), ",") {
+			if version := n.versionInMediaType(each); len(version) > 0 {
+				return version, true
+			}
+		}
+	}
+	if len(n.Header) > 0 {
+		if version := httpRequest.Header.Get(n.Header); len(version) > 0 {
+			return version, false
+		}
+	}
+	if len(n.QueryParameter) > 0 {
+		if version := httpRequest.URL.Query().Get(n.QueryParameter); len(version) > 0 {
+			return version, false
+		}
+	}
+	return "", false
+}
+
+// versionInMediaType returns the version from e.g. application/vnd.acme.v2+json or application/json;version=2
+func (n ApiVersionNegotiation) versionInMediaType(mediaType string) string {
+	parts := strings.Split(mediaType, ";")
+	for _, each := range parts[1:] {
+		keyValue := strings.SplitN(strings.TrimSpace(each), "=", 2)
+		if len(keyValue) == 2 && strings.TrimSpace(keyValue[0]) == "version" {
+			return strings.Trim(strings.TrimSpace(keyValue[1]), `"`)
+		}
+	}
+	typeAndSubtype := strings.SplitN(strings.TrimSpace(parts[0]), "/", 2)
+	if len(typeAndSubtype) != 2 {
+		return ""
+	}
+	subtype := typeAndSubtype[1]
+	if plus := strings.Index(subtype, "+"); plus != -1 {
+		subtype = subtype[:plus]
+	}
+	prefix := n.MediaTypeVendor + ".v"
+	if !strings.HasPrefix(subtype, prefix) {
+		return ""
+	}
+	return subtype[len(prefix):]
+}
+
+// routingRequest returns the request to select a Route with. In its Accept header, each versioned vendor media type is
+// followed by the media type of its suffix, e.g. application/vnd.acme.v2+json => application/vnd.acme.v2+json, application/json,
+// such that a Route that produces JSON can be selected for the negotiated version.
+func (n ApiVersionNegotiation) routingRequest(httpRequest *http.Request) *http.Request {
+	accept := httpRequest.Header.Get(HEADER_Accept)
+	if len(n.MediaTypeVendor) == 0 || !strings.Contains(accept, "+") {
+		return httpRequest
+	}
+	expanded := []string{}
+	for _, each := range strings.Split(accept, ",") {
+		expanded = append(expanded, each)
+		parsed := parseMediaType(each)
+		if len(parsed.suffix) == 0 || len(n.versionInMediaType(each)) == 0 {
+			continue
+		}
+		// keep the parameters, e.g. the quality
+		general := parsed.mainType + "/" + parsed.suffix
+		if semi := strings.Index(each, ";"); semi != -1 {
+			general += each[semi:]
+		}
+		expanded = append(expanded, general)
+	}
+	copied := new(http.Request)
+	*copied = *httpRequest
+	copied.Header = httpRequest.Header.Clone()
+	copied.Header.Set(HEADER_Accept, strings.Join(expanded, ","))
+	return copied
+}
+
+// apiVersionGroup is either a WebService without ApiVersion or
+// all WebServices with an ApiVersion that have the same root path and hosts, in the order they were added.
+type apiVersionGroup []*WebService
+
+// groupByApiVersion returns the groups of WebServices in the order of their first WebService.
+func groupByApiVersion(webServices []*WebService) []apiVersionGroup {
+	groups := []apiVersionGroup{}
+	indexByRoot := map[string]int{}
+	for _, each := range webServices {
+		if len(each.apiVersion) == 0 {
+			groups = append(groups, apiVersionGroup{each})
+			continue
+		}
+		root := each.rootPath + " " + strings.Join(each.Hosts(), " ")
+		if index, ok := indexByRoot[root]; ok {
+			groups[index] = append(groups[index], each)
+			continue
+		}
+		indexByRoot[root] = len(groups)
+		groups = append(groups, apiVersionGroup{each})
+	}
+	return groups
+}
+
+// selectWebServices returns the WebServices for the requested version.
+// From each group of WebServices with the same root path and different ApiVersion, only one is kept.
+func (n ApiVersionNegotiation) selectWebServices(groups []apiVersionGroup, httpRequest *http.Request) ([]*WebService, error) {
+	requested, fromAccept := n.requestedVersion(httpRequest)
+	explicit := len(requested) > 0
+	if !explicit {
+		requested = n.Default
+	}
+	selected := make([]*WebService, 0, len(groups))
+	for _, group := range groups {
+		if len(group[0].apiVersion) == 0 {
+			selected = append(selected, group[0])
+			continue
+		}
+		var match *WebService
+		for _, each := range group {
+			if sameApiVersion(each.apiVersion, requested) {
+				match = each
+				break
+			}
+		}
+		if match == nil && !explicit {
+			match = group[0]
+		}
+		if match != nil {
+			selected = append(selected, match)
+			continue
+		}
+		if group[0].pathExpr.Matcher.MatchString(httpRequest.URL.Path) && group[0].matchesHost(hostWithoutPort(httpRequest.Host)) {
+			return nil, unsupportedApiVersionError(requested, fromAccept, group)
+		}
+	}
+	return selected, nil
+}
+
+func unsupportedApiVersionError(requested string, fromAccept bool, group apiVersionGroup) ServiceError {
+	available := make([]string, 0, len(group))
+	for _, each := range group {
+		available = append(available, each.apiVersion)
+	}
+	if trace {
+		traceLogger.Printf("no WebService with path %s has API version %s\n", group[0].rootPath, requested)
+	}
+	if fromAccept {
+		return NewError(
+			http.StatusNotAcceptable,
+			fmt.Sprintf("406: Not Acceptable\n\nAvailable API versions: %s", strings.Join(available, ", ")),
+		)
+	}
+	return NewError(
+		http.StatusBadRequest,
+		fmt.Sprintf("400: Unsupported API version %s\n\nAvailable API versions: %s", requested, strings.Join(available, ", ")),
+	)
+}
+
+// sameApiVersion compares two versions ignoring a leading v, e.g. v2 equals 2
+func sameApiVersion(version, other string) bool {
+	return strings.TrimPrefix(strings.ToLower(version), "v") == strings.TrimPrefix(strings.ToLower(other), "v")
+}
@@ -0,0 +1,196 @@
+package restful
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type versionedUser struct {
+	Version string
+}
+
+// newVersionedUserService returns a WebService that only produces JSON, as most versioned WebServices do.
+func newVersionedUserService(version string) *WebService {
+	ws := new(WebService).Path("/users").ApiVersion(version).Produces(MIME_JSON)
+	ws.Route(ws.GET("/{id}").To(func(req *Request, resp *Response) {
+		resp.WriteEntity(versionedUser{Version: version})
+	}).Operation("findUser"))
+	return ws
+}
+
+func newVersionedContainer() *Container {
+	wc := NewContainer()
+	wc.ApiVersionNegotiation(ApiVersionNegotiation{
+		MediaTypeVendor: "vnd.acme",
+		Header:          "Api-Version",
+		QueryParameter:  "api-version",
+		Default:         "1",
+	})
+	wc.Add(newVersionedUserService("1"))
+	wc.Add(newVersionedUserService("2"))
+	health := new(WebService).Path("/health")
+	health.Route(health.GET("").To(dummy))
+	wc.Add(health)
+	return wc
+}
+
+// go test -v -test.run TestApiVersion_Default ...restful
+func TestApiVersion_Default(t *testing.T) {
+	httpWriter := dispatchRequest(newVersionedContainer(), "GET", "/users/1", nil, "")
+	if !strings.Contains(httpWriter.Body.String(), `"Version": "1"`) {
+		t.Error("default version expected, got", httpWriter.Body.String())
+	}
+}
+
+// go test -v -test.run TestApiVersion_VendorMediaTypeSelectsJSONRoute ...restful
+func TestApiVersion_VendorMediaTypeSelectsJSONRoute(t *testing.T) {
+	header := http.Header{"Accept": {"application/vnd.acme.v2+json"}}
+	httpWriter := dispatchRequest(newVersionedContainer(), "GET", "/users/1", header, "")
+	if 200 != httpWriter.Code {
+		t.Fatalf("200 expected for a Route that produces JSON, got %d: %s", httpWriter.Code, httpWriter.Body.String())
+	}
+	if !strings.Contains(httpWriter.Body.String(), `"Version": "2"`) {
+		t.Error("version 2 expected, got", httpWriter.Body.String())
+	}
+	if MIME_JSON != httpWriter.Header().Get(HEADER_ContentType) {
+		t.Error("produced media type expected as Content-Type, got", httpWriter.Header().Get(HEADER_ContentType))
+	}
+}
+
+// go test -v -test.run TestApiVersion_VendorMediaTypeProduced ...restful
+func TestApiVersion_VendorMediaTypeProduced(t *testing.T) {
+	wc := NewContainer()
+	wc.ApiVersionNegotiation(ApiVersionNegotiation{MediaTypeVendor: "vnd.acme"})
+	wc.Add(newVersionedUserService("1"))
+	ws := new(WebService).Path("/users").ApiVersion("2").Produces(MIME_JSON, "application/vnd.acme.v2+json")
+	ws.Route(ws.GET("/{id}").To(func(req *Request, resp *Response) {
+		resp.WriteEntity(versionedUser{Version: "2"})
+	}).Operation("findUser"))
+	wc.Add(ws)
+	header := http.Header{"Accept": {"application/vnd.acme.v2+json"}}
+	httpWriter := dispatchRequest(wc, "GET", "/users/1", header, "")
+	if "application/vnd.acme.v2+json" != httpWriter.Header().Get(HEADER_ContentType) {
+		t.Error("vendor media type expected as Content-Type, got", httpWriter.Header().Get(HEADER_ContentType))
+	}
+}
+
+// go test -v -test.run TestApiVersion_VendorMediaTypeWithQuality ...restful
+func TestApiVersion_VendorMediaTypeWithQuality(t *testing.T) {
+	header := http.Header{"Accept": {"text/plain;q=0.5, application/vnd.acme.v2+json;q=0.9"}}
+	httpWriter := dispatchRequest(newVersionedContainer(), "GET", "/users/1", header, "")
+	if 200 != httpWriter.Code || !strings.Contains(httpWriter.Body.String(), `"Version": "2"`) {
+		t.Errorf("version 2 expected, got %d: %s", httpWriter.Code, httpWriter.Body.String())
+	}
+}
+
+// go test -v -test.run TestApiVersion_VendorMediaTypeOfOtherFormat ...restful
+func TestApiVersion_VendorMediaTypeOfOtherFormat(t *testing.T) {
+	header := http.Header{"Accept": {"application/vnd.acme.v2+xml"}}
+	httpWriter := dispatchRequest(newVersionedContainer(), "GET", "/users/1", header, "")
+	if 406 != httpWriter.Code {
+		t.Error("406 expected because the Route only produces JSON, got", httpWriter.Code)
+	}
+}
+
+// go test -v -test.run TestApiVersion_MediaTypeParameter ...restful
+func TestApiVersion_MediaTypeParameter(t *testing.T) {
+	header := http.Header{"Accept": {"application/json;version=2"}}
+	httpWriter := dispatchRequest(newVersionedContainer(), "GET", "/users/1", header, "")
+	if !strings.Contains(httpWriter.Body.String(), `"Version": "2"`) {
+		t.Error("version 2 expected, got", httpWriter.Body.String())
+	}
+}
+
+// go test -v -test.run TestApiVersion_HeaderOverridesQueryParameter ...restful
+func TestApiVersion_HeaderOverridesQueryParameter(t *testing.T) {
+	wc := newVersionedContainer()
+	httpWriter := dispatchRequest(wc, "GET", "/users/1?api-version=2", nil, "")
+	if !strings.Contains(httpWriter.Body.String(), `"Version": "2"`) {
+		t.Error("version 2 of query parameter expected, got", httpWriter.Body.String())
+	}
+	httpWriter = dispatchRequest(wc, "GET", "/users/1?api-version=2", http.Header{"Api-Version": {"v1"}}, "")
+	if !strings.Contains(httpWriter.Body.String(), `"Version": "1"`) {
+		t.Error("version 1 of header expected, got", httpWriter.Body.String())
+	}
+}
+
+// go test -v -test.run TestApiVersion_UnknownVersion ...restful
+func TestApiVersion_UnknownVersion(t *testing.T) {
+	wc := newVersionedContainer()
+	httpWriter := dispatchRequest(wc, "GET", "/users/1", http.Header{"Accept": {"application/vnd.acme.v3+json"}}, "")
+	if 406 != httpWriter.Code {
+		t.Error("406 expected for unknown version in Accept, got", httpWriter.Code)
+	}
+	if !strings.Contains(httpWriter.Body.String(), "Available API versions: 1, 2") {
+		t.Error("available versions expected, got", httpWriter.Body.String())
+	}
+	httpWriter = dispatchRequest(wc, "GET", "/users/1", http.Header{"Api-Version": {"3"}}, "")
+	if 400 != httpWriter.Code {
+		t.Error("400 expected for unknown version in header, got", httpWriter.Code)
+	}
+}
+
+// go test -v -test.run TestApiVersion_UnversionedWebService ...restful
+func TestApiVersion_UnversionedWebService(t *testing.T) {
+	httpWriter := dispatchRequest(newVersionedContainer(), "GET", "/health", http.Header{"Api-Version": {"3"}}, "")
+	if 200 != httpWriter.Code {
+		t.Error("version must be ignored for a WebService without ApiVersion, got", httpWriter.Code)
+	}
+}
+
+// go test -v -test.run TestApiVersion_NoRouteConflicts ...restful
+func TestApiVersion_NoRouteConflicts(t *testing.T) {
+	if conflicts := newVersionedContainer().RouteConflicts(); len(conflicts) != 0 {
+		t.Error("no conflicts expected between versions, got", conflicts)
+	}
+}
+
+// go test -v -test.run TestApiVersionNegotiation_Remove ...restful
+func TestApiVersionNegotiation_Remove(t *testing.T) {
+	wc := newVersionedContainer()
+	v2 := new(WebService).Path("/users").ApiVersion("2")
+	if err := wc.Remove(v2); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(wc.RegisteredWebServices()), 2; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	httpWriter := dispatchRequest(wc, "GET", "/users/1", http.Header{"Api-Version": {"2"}}, "")
+	if 400 != httpWriter.Code {
+		t.Error("400 expected for removed version, got", httpWriter.Code)
+	}
+}
+
+// go test -v -test.run TestApiVersionDuplicateRoot ...restful
+func TestApiVersionDuplicateRoot(t *testing.T) {
+	wc := NewContainer()
+	wc.Add(new(WebService).Path("/users").ApiVersion("1"))
+	v2 := new(WebService).Path("/users").ApiVersion("2")
+	if _, ok := wc.duplicateRootOf(v2); !ok {
+		t.Error("expected duplicate root without version negotiation")
+	}
+	wc.ApiVersionNegotiation(ApiVersionNegotiation{Default: "1"})
+	if _, ok := wc.duplicateRootOf(v2); ok {
+		t.Error("expected no duplicate root with version negotiation")
+	}
+	if _, ok := wc.duplicateRootOf(new(WebService).Path("/users").ApiVersion("v1")); !ok {
+		t.Error("expected duplicate root for the same version")
+	}
+}
+
+// go test -v -test.run TestApiVersionStrictParameters ...restful
+func TestApiVersionStrictParameters(t *testing.T) {
+	wc := NewContainer()
+	wc.ApiVersionNegotiation(ApiVersionNegotiation{Header: "X-Api-Version", QueryParameter: "api-version", Default: "1"})
+	ws := new(WebService).Path("/users").ApiVersion("1").StrictQueryParameters(true).StrictHeaderParameters(true)
+	ws.Route(ws.GET("/{id}").To(dummy).Operation("findUser"))
+	wc.Add(ws)
+	header := http.Header{"X-Api-Version": {"1"}}
+	if httpWriter := dispatchRequest(wc, "GET", "/users/1?api-version=1", header, ""); 200 != httpWriter.Code {
+		t.Error("version parameters must be accepted, got", httpWriter.Code, httpWriter.Body.String())
+	}
+	if httpWriter := dispatchRequest(wc, "GET", "/users/1?api-version=1&verbose=1", header, ""); 400 != httpWriter.Code {
+		t.Error("400 expected for unknown query parameter, got", httpWriter.Code)
+	}
+}
@@ -0,0 +1,110 @@
+package restful
+
+// Copyright 2024 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// EnableAutoHead (default=false) allows HEAD requests for paths that only have a GET Route.
+// The GET Route function is called with a Response that discards the body but keeps the headers and sets the Content-Length.
+// The method of the Request remains HEAD. Use the WebService EnableAutoHead to override this value.
+// No Content-Length is set if the response is compressed because the compressed length is not known.
+func (c *Container) EnableAutoHead(enabled bool) {
+	c.autoHeadEnabled = enabled
+}
+
+// EnableAutoHead allows HEAD requests for paths that only have a GET Route. Overrides the container.autoHeadEnabled value.
+func (w *WebService) EnableAutoHead(enabled bool) *WebService {
+	w.autoHeadEnabled = &enabled
+	return w
+}
+
+// autoHeadEnabledFor returns whether HEAD requests can be served by the GET Routes of the WebService.
+func (c *Container) autoHeadEnabledFor(webService *WebService) bool {
+	if webService.autoHeadEnabled != nil {
+		return *webService.autoHeadEnabled
+	}
+	return c.autoHeadEnabled
+}
+
+// selectGETRouteForHEAD tries to select a GET Route for a HEAD request for which no Route was selected.
+// this function must run inside the critical region protected by the webServicesLock.
+func (c *Container) selectGETRouteForHEAD(webServices []*WebService, httpRequest *http.Request, err error) (*WebService, *Route, string, bool) {
+	if httpRequest.Method != http.MethodHead {
+		return nil, nil, "", false
+	}
+	if ser, ok := err.(ServiceError); !ok || (ser.Code != http.StatusNotFound && ser.Code != http.StatusMethodNotAllowed) {
+		return nil, nil, "", false
+	}
+	getRequest := new(http.Request)
+	*getRequest = *httpRequest
+	getRequest.Method = http.MethodGet
+	webService, route, routePath, err := c.selectRoute(webServices, getRequest)
+	if err != nil || !c.autoHeadEnabledFor(webService) {
+		return nil, nil, "", false
+	}
+	if trace {
+		traceLogger.Printf("using GET Route %s for HEAD request\n", route.Path)
+	}
+	return webService, route, routePath, true
+}
+
+// headResponseWriter discards the body written by a GET Route function for a HEAD request.
+// It delays writing the header such that the Content-Length can be set to the number of bytes that would have been written.
+type headResponseWriter struct {
+	http.ResponseWriter
+	statusCode    int // zero if not set explicitly
+	contentLength int
+	headerWritten bool // whether the header was written to the actual ResponseWriter
+}
+
+// WriteHeader is part of http.ResponseWriter ; it is delayed until the response is finished or flushed.
+func (h *headResponseWriter) WriteHeader(status int) {
+	if h.statusCode == 0 {
+		h.statusCode = status
+	}
+}
+
+// Write is part of http.ResponseWriter ; it only counts the bytes.
+func (h *headResponseWriter) Write(bytes []byte) (int, error) {
+	h.contentLength += len(bytes)
+	return len(bytes), nil
+}
+
+// Flush is part of http.Flusher ; it writes the header without Content-Length.
+func (h *headResponseWriter) Flush() {
+	h.writeHeader(false)
+	if flusher, ok := h.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// finish writes the header, if not already written, including the Content-Length.
+func (h *headResponseWriter) finish() {
+	h.writeHeader(true)
+}
+
+// compressing returns whether the GET response would have been compressed, e.g. by the ServeHTTP of the Container,
+// in which case the number of bytes written is not the Content-Length.
+func (h *headResponseWriter) compressing() bool {
+	_, ok := h.ResponseWriter.(*CompressingResponseWriter)
+	return ok
+}
+
+func (h *headResponseWriter) writeHeader(withContentLength bool) {
+	if h.headerWritten {
+		return
+	}
+	h.headerWritten = true
+	if withContentLength && h.contentLength > 0 && !h.compressing() && len(h.Header().Get("Content-Length")) == 0 && len(h.Header().Get("Transfer-Encoding")) == 0 {
+		h.Header().Set("Content-Length", strconv.Itoa(h.contentLength))
+	}
+	if h.statusCode == 0 {
+		h.statusCode = http.StatusOK
+	}
+	h.ResponseWriter.WriteHeader(h.statusCode)
+}
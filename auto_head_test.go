@@ -0,0 +1,188 @@
+package restful
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newUserGetOnlyService() *WebService {
+	ws := new(WebService).Path("/users")
+	ws.Route(ws.GET("/{id}").To(func(req *Request, resp *Response) {
+		resp.AddHeader("X-Method", req.Request.Method)
+		resp.WriteHeader(http.StatusAccepted)
+		resp.Write([]byte("hello"))
+	}).Operation("findUser"))
+	ws.Route(ws.PUT("/{id}").To(dummy).Operation("updateUser"))
+	return ws
+}
+
+// go test -v -test.run TestAutoHead_Disabled ...restful
+func TestAutoHead_Disabled(t *testing.T) {
+	wc := NewContainer()
+	wc.Add(newUserGetOnlyService())
+	httpWriter := dispatchRequest(wc, "HEAD", "/users/42", nil, "")
+	if 405 != httpWriter.Code {
+		t.Error("405 expected for HEAD without a HEAD Route, got", httpWriter.Code)
+	}
+}
+
+// go test -v -test.run TestAutoHead_UsesGETRoute ...restful
+func TestAutoHead_UsesGETRoute(t *testing.T) {
+	wc := NewContainer()
+	wc.EnableAutoHead(true)
+	wc.Add(newUserGetOnlyService())
+	httpWriter := dispatchRequest(wc, "HEAD", "/users/42", nil, "")
+	if 202 != httpWriter.Code {
+		t.Error("status of the GET Route expected, got", httpWriter.Code)
+	}
+	if httpWriter.Body.Len() != 0 {
+		t.Errorf("no body expected, got %q", httpWriter.Body.String())
+	}
+	if "5" != httpWriter.Header().Get("Content-Length") {
+		t.Error("Content-Length of the GET body expected, got", httpWriter.Header().Get("Content-Length"))
+	}
+	if "HEAD" != httpWriter.Header().Get("X-Method") {
+		t.Error("method of the Request must remain HEAD, got", httpWriter.Header().Get("X-Method"))
+	}
+}
+
+// go test -v -test.run TestAutoHead_DoesNotCompressWithDispatch ...restful
+func TestAutoHead_DoesNotCompressWithDispatch(t *testing.T) {
+	wc := NewContainer()
+	wc.EnableAutoHead(true)
+	wc.EnableContentEncoding(true)
+	wc.Add(newUserGetOnlyService())
+	httpWriter := dispatchRequest(wc, "HEAD", "/users/42", http.Header{"Accept-Encoding": {"gzip"}}, "")
+	if got := httpWriter.Header().Get("Content-Encoding"); got != "" {
+		t.Error("no Content-Encoding expected, got", got)
+	}
+	if "5" != httpWriter.Header().Get("Content-Length") {
+		t.Error("Content-Length of the GET body expected, got", httpWriter.Header().Get("Content-Length"))
+	}
+}
+
+// go test -v -test.run TestAutoHead_NoContentLengthIfCompressedByServeHTTP ...restful
+func TestAutoHead_NoContentLengthIfCompressedByServeHTTP(t *testing.T) {
+	wc := NewContainer()
+	wc.EnableAutoHead(true)
+	wc.EnableContentEncoding(true)
+	wc.Add(newUserGetOnlyService())
+	httpRequest := httptest.NewRequest("HEAD", "/users/42", nil)
+	httpRequest.Header.Set("Accept-Encoding", "gzip")
+	httpWriter := httptest.NewRecorder()
+	wc.ServeHTTP(httpWriter, httpRequest)
+	if "gzip" != httpWriter.Header().Get("Content-Encoding") {
+		t.Fatal("gzip expected, got", httpWriter.Header().Get("Content-Encoding"))
+	}
+	// the uncompressed length would be wrong for the compressed GET response
+	if got := httpWriter.Header().Get("Content-Length"); got != "" {
+		t.Error("no Content-Length expected, got", got)
+	}
+}
+
+// go test -v -test.run TestAutoHead_PanicInGETRoute ...restful
+func TestAutoHead_PanicInGETRoute(t *testing.T) {
+	wc := NewContainer()
+	wc.DoNotRecover(false)
+	wc.EnableAutoHead(true)
+	wc.Add(newPanicingService())
+	httpWriter := dispatchRequest(wc, "HEAD", "/fire", nil, "")
+	if 500 != httpWriter.Code {
+		t.Error("500 expected on fire, got", httpWriter.Code)
+	}
+	if httpWriter.Body.Len() != 0 {
+		t.Errorf("no body expected, got %q", httpWriter.Body.String())
+	}
+}
+
+// go test -v -test.run TestAutoHead_PanicAfterWriteHeader ...restful
+func TestAutoHead_PanicAfterWriteHeader(t *testing.T) {
+	wc := NewContainer()
+	wc.DoNotRecover(false)
+	wc.EnableAutoHead(true)
+	ws := new(WebService).Path("/fire")
+	ws.Route(ws.GET("").To(func(req *Request, resp *Response) {
+		resp.WriteHeader(http.StatusOK)
+		resp.Write([]byte("partial"))
+		panic("fire")
+	}).Operation("fire"))
+	wc.Add(ws)
+	// the delayed status of the GET Route is replaced because nothing was sent yet
+	httpWriter := dispatchRequest(wc, "HEAD", "/fire", nil, "")
+	if 500 != httpWriter.Code {
+		t.Error("500 expected on fire, got", httpWriter.Code)
+	}
+}
+
+// go test -v -test.run TestAutoHead_UnknownPath ...restful
+func TestAutoHead_UnknownPath(t *testing.T) {
+	wc := NewContainer()
+	wc.EnableAutoHead(true)
+	wc.Add(newUserGetOnlyService())
+	httpWriter := dispatchRequest(wc, "HEAD", "/orders/42", nil, "")
+	if 404 != httpWriter.Code {
+		t.Error("404 expected, got", httpWriter.Code)
+	}
+}
+
+// go test -v -test.run TestAutoHead_HEADRouteTakesPrecedence ...restful
+func TestAutoHead_HEADRouteTakesPrecedence(t *testing.T) {
+	wc := NewContainer()
+	wc.EnableAutoHead(true)
+	ws := newUserGetOnlyService()
+	ws.Route(ws.HEAD("/{id}").To(return204).Operation("existsUser"))
+	wc.Add(ws)
+	httpWriter := dispatchRequest(wc, "HEAD", "/users/42", nil, "")
+	if 204 != httpWriter.Code {
+		t.Error("204 of the HEAD Route expected, got", httpWriter.Code)
+	}
+}
+
+// go test -v -test.run TestAutoHead_WebServiceOverride ...restful
+func TestAutoHead_WebServiceOverride(t *testing.T) {
+	wc := NewContainer()
+	wc.EnableAutoHead(true)
+	wc.Add(newUserGetOnlyService().EnableAutoHead(false))
+	httpWriter := dispatchRequest(wc, "HEAD", "/users/42", nil, "")
+	if 405 != httpWriter.Code {
+		t.Error("405 expected if disabled for the WebService, got", httpWriter.Code)
+	}
+}
+
+// go test -v -test.run TestAutoHead_AllowedMethods ...restful
+func TestAutoHead_AllowedMethods(t *testing.T) {
+	wc := NewContainer()
+	wc.EnableAutoHead(true)
+	wc.Add(newUserGetOnlyService())
+	wc.Filter(wc.OPTIONSFilter)
+	httpWriter := dispatchRequest(wc, "OPTIONS", "/users/42", nil, "")
+	if "GET,PUT,HEAD" != httpWriter.Header().Get(HEADER_Allow) {
+		t.Error("HEAD expected in Allow header, got", httpWriter.Header().Get(HEADER_Allow))
+	}
+	wc.EnableAutoHead(false)
+	httpWriter = dispatchRequest(wc, "OPTIONS", "/users/42", nil, "")
+	if strings.Contains(httpWriter.Header().Get(HEADER_Allow), "HEAD") {
+		t.Error("no HEAD expected in Allow header, got", httpWriter.Header().Get(HEADER_Allow))
+	}
+}
+
+// go test -v -test.run TestAutoHead_GETStillCompressed ...restful
+func TestAutoHead_GETStillCompressed(t *testing.T) {
+	wc := NewContainer()
+	wc.EnableAutoHead(true)
+	wc.EnableContentEncoding(true)
+	wc.Add(newUserGetOnlyService())
+	httpWriter := dispatchRequest(wc, "GET", "/users/42", http.Header{"Accept-Encoding": {"gzip"}}, "")
+	reader, err := gzip.NewReader(httpWriter.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(reader)
+	if "hello" != string(body) {
+		t.Errorf("hello expected, got %q", body)
+	}
+}
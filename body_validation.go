@@ -0,0 +1,138 @@
+package restful
+
+// Copyright 2024 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// EnableBodyValidation (default=false) checks the JSON body of a request against the JSONSchemaOf the Reads sample
+// of the selected Route before its RouteFunction is called (after all filters).
+// Bodies with a Content-Encoding (gzip, deflate) are decompressed first. Bodies of other content types are not checked.
+// An empty body is only rejected for POST, PUT and PATCH requests and if the body parameter is Required (as documented by Reads).
+// All violations are reported in a single ServiceError with status 400 (Bad Request) that is passed to the ServiceErrorHandler.
+// Use the RouteBuilder BodyValidationEnabled to override this value.
+func (c *Container) EnableBodyValidation(enabled bool) {
+	c.bodyValidationEnabled = enabled
+}
+
+// defaultBodyValidationLimit is the maximum number of bytes of a body that is read for validation, see BodyValidationLimit.
+const defaultBodyValidationLimit = 10 << 20
+
+// BodyValidationLimit (default=10 MB) sets the maximum number of bytes of a (decompressed) JSON body that is read for validation.
+// A larger body is answered with a ServiceError with status 413 (Request Entity Too Large).
+func (c *Container) BodyValidationLimit(maxBytes int64) {
+	c.bodyValidationLimit = maxBytes
+}
+
+// BodyValidationEnabled allows you to override the Containers value for validating the body of this route.
+func (b *RouteBuilder) BodyValidationEnabled(enabled bool) *RouteBuilder {
+	b.bodyValidationEnabled = &enabled
+	return b
+}
+
+// bodyValidationEnabledFor returns whether the body of requests for the Route must be validated.
+func (c *Container) bodyValidationEnabledFor(route *Route) bool {
+	if route.ReadSample == nil {
+		return false
+	}
+	if route.bodyValidationEnabled != nil {
+		return *route.bodyValidationEnabled
+	}
+	return c.bodyValidationEnabled
+}
+
+// validatingBody returns a RouteFunction that validates the body before calling the function.
+func (c *Container) validatingBody(route *Route, function RouteFunction) RouteFunction {
+	schema := JSONSchemaOf(route.ReadSample)
+	limit := c.bodyValidationLimit
+	if limit <= 0 {
+		limit = defaultBodyValidationLimit
+	}
+	// Reads documents a required body ; use ParameterNamed("body").Required(false) to make it optional
+	required := false
+	for _, each := range route.ParameterDocs {
+		if each.data.Kind == BodyParameterKind && each.data.Required {
+			required = true
+		}
+	}
+	return func(req *Request, resp *Response) {
+		if err := req.validateBody(schema, limit, required && methodHasBody(req.Request.Method)); err != nil {
+			c.serviceErrorHandleFunc(err.(ServiceError), req, resp)
+			return
+		}
+		function(req, resp)
+	}
+}
+
+// methodHasBody returns whether requests with the method are expected to have a body.
+func methodHasBody(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch
+}
+
+// validateBody reads and checks a JSON body, returning a ServiceError for violations, and replaces it such that it can be read again.
+// A compressed body is replaced by its decompressed content. An empty body is only a violation if it is required.
+func (r *Request) validateBody(schema *JSONSchema, limit int64, required bool) error {
+	contentType := r.Request.Header.Get(HEADER_ContentType)
+	if len(contentType) == 0 || strings.HasPrefix(contentType, "*/*") {
+		contentType = defaultRequestContentType
+	}
+	if !isJSONContentType(contentType) || r.Request.Body == nil {
+		return nil
+	}
+	var reader io.Reader = r.Request.Body
+	switch r.Request.Header.Get(HEADER_ContentEncoding) {
+	case ENCODING_GZIP:
+		gzipReader := currentCompressorProvider.AcquireGzipReader()
+		defer currentCompressorProvider.ReleaseGzipReader(gzipReader)
+		if err := gzipReader.Reset(r.Request.Body); err != nil {
+			return invalidBody("#: invalid gzip content: " + err.Error())
+		}
+		reader = gzipReader
+	case ENCODING_DEFLATE:
+		zlibReader, err := zlib.NewReader(r.Request.Body)
+		if err != nil {
+			return invalidBody("#: invalid deflate content: " + err.Error())
+		}
+		reader = zlibReader
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(reader, limit+1))
+	if err != nil {
+		return invalidBody("#: unable to read body: " + err.Error())
+	}
+	if int64(len(body)) > limit {
+		return NewError(http.StatusRequestEntityTooLarge, fmt.Sprintf("413: Request Entity Too Large: body exceeds %d bytes", limit))
+	}
+	r.Request.Body.Close()
+	r.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	r.Request.Header.Del(HEADER_ContentEncoding)
+	r.Request.ContentLength = int64(len(body))
+	if len(bytes.TrimSpace(body)) == 0 {
+		if required {
+			return invalidBody("#: missing body")
+		}
+		return nil
+	}
+	if problems := schema.Validate(body); len(problems) > 0 {
+		return invalidBody(problems...)
+	}
+	return nil
+}
+
+func invalidBody(problems ...string) error {
+	return newInvalidBodyError(problems)
+}
+
+// isJSONContentType returns whether the media type is application/json or has the +json suffix.
+func isJSONContentType(contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	return mediaType == MIME_JSON || strings.HasSuffix(mediaType, "+json")
+}
@@ -0,0 +1,171 @@
+package restful
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"testing"
+)
+
+var jsonContent = http.Header{"Content-Type": {MIME_JSON}}
+
+// newCreateUserService returns a WebService that reads a schemaUser and echoes its name.
+func newCreateUserService() *WebService {
+	ws := new(WebService).Path("/users").Consumes(MIME_JSON, "application/vnd.acme+json", MIME_XML)
+	ws.Route(ws.POST("").Reads(schemaUser{}).To(func(req *Request, resp *Response) {
+		user := new(schemaUser)
+		if err := req.ReadEntity(user); err != nil {
+			resp.WriteError(http.StatusBadRequest, err)
+			return
+		}
+		io.WriteString(resp, user.Name)
+	}).Operation("createUser"))
+	return ws
+}
+
+func newBodyValidationContainer(ws *WebService) *Container {
+	wc := NewContainer()
+	wc.EnableBodyValidation(true)
+	wc.Add(ws)
+	return wc
+}
+
+// go test -v -test.run TestBodyValidation_BodyCanBeReadAfterValidation ...restful
+func TestBodyValidation_BodyCanBeReadAfterValidation(t *testing.T) {
+	wc := newBodyValidationContainer(newCreateUserService())
+	httpWriter := dispatchRequest(wc, "POST", "/users", jsonContent, `{"name":"John"}`)
+	if 200 != httpWriter.Code || "John" != httpWriter.Body.String() {
+		t.Errorf("entity expected, got %d: %s", httpWriter.Code, httpWriter.Body.String())
+	}
+}
+
+// go test -v -test.run TestBodyValidation_ReportsAllProblems ...restful
+func TestBodyValidation_ReportsAllProblems(t *testing.T) {
+	wc := newBodyValidationContainer(newCreateUserService())
+	httpWriter := dispatchRequest(wc, "POST", "/users", jsonContent, `{"age":-1}`)
+	if 400 != httpWriter.Code {
+		t.Error("400 expected, got", httpWriter.Code)
+	}
+	if want := "400: Invalid body: #/name: missing required property; #/age: value -1 is less than minimum 0"; want != httpWriter.Body.String() {
+		t.Errorf("got %q want %q", httpWriter.Body.String(), want)
+	}
+}
+
+// go test -v -test.run TestBodyValidation_StructuredSyntaxSuffix ...restful
+func TestBodyValidation_StructuredSyntaxSuffix(t *testing.T) {
+	wc := newBodyValidationContainer(newCreateUserService())
+	httpWriter := dispatchRequest(wc, "POST", "/users", http.Header{"Content-Type": {"application/vnd.acme+json"}}, `{}`)
+	if 400 != httpWriter.Code {
+		t.Error("a +json body must be validated, got", httpWriter.Code)
+	}
+}
+
+// go test -v -test.run TestBodyValidation_OtherContentTypeNotChecked ...restful
+func TestBodyValidation_OtherContentTypeNotChecked(t *testing.T) {
+	wc := newBodyValidationContainer(newCreateUserService())
+	httpWriter := dispatchRequest(wc, "POST", "/users", http.Header{"Content-Type": {MIME_XML}}, `<schemaUser><Name>x</Name></schemaUser>`)
+	if 200 != httpWriter.Code {
+		t.Error("an XML body must not be validated, got", httpWriter.Code)
+	}
+}
+
+// go test -v -test.run TestBodyValidation_Gzipped ...restful
+func TestBodyValidation_Gzipped(t *testing.T) {
+	var zipped bytes.Buffer
+	gz := gzip.NewWriter(&zipped)
+	gz.Write([]byte(`{"name":"Jane"}`))
+	gz.Close()
+	wc := newBodyValidationContainer(newCreateUserService())
+	header := http.Header{"Content-Type": {MIME_JSON}, "Content-Encoding": {ENCODING_GZIP}}
+	httpWriter := dispatchRequest(wc, "POST", "/users", header, zipped.String())
+	if 200 != httpWriter.Code || "Jane" != httpWriter.Body.String() {
+		t.Errorf("decompressed entity expected, got %d: %s", httpWriter.Code, httpWriter.Body.String())
+	}
+}
+
+// go test -v -test.run TestBodyValidation_MissingBody ...restful
+func TestBodyValidation_MissingBody(t *testing.T) {
+	wc := newBodyValidationContainer(newCreateUserService())
+	httpWriter := dispatchRequest(wc, "POST", "/users", jsonContent, "")
+	if 400 != httpWriter.Code || "400: Invalid body: #: missing body" != httpWriter.Body.String() {
+		t.Errorf("missing body expected, got %d: %s", httpWriter.Code, httpWriter.Body.String())
+	}
+}
+
+// go test -v -test.run TestBodyValidation_NoBodyRequiredForGET ...restful
+func TestBodyValidation_NoBodyRequiredForGET(t *testing.T) {
+	ws := new(WebService).Path("/users")
+	ws.Route(ws.GET("").Reads(schemaUser{}).To(dummy).Operation("listUsers"))
+	httpWriter := dispatchRequest(newBodyValidationContainer(ws), "GET", "/users", nil, "")
+	if 200 != httpWriter.Code {
+		t.Error("a GET without body must not be rejected, got", httpWriter.Code)
+	}
+}
+
+// go test -v -test.run TestBodyValidation_OptionalBody ...restful
+func TestBodyValidation_OptionalBody(t *testing.T) {
+	ws := new(WebService).Path("/users")
+	optional := ws.PATCH("").Reads(schemaUser{})
+	optional.ParameterNamed("body").Required(false)
+	ws.Route(optional.To(dummy).Operation("patchUser"))
+	wc := newBodyValidationContainer(ws)
+	if httpWriter := dispatchRequest(wc, "PATCH", "/users", jsonContent, ""); 200 != httpWriter.Code {
+		t.Error("an optional body can be absent, got", httpWriter.Code)
+	}
+	if httpWriter := dispatchRequest(wc, "PATCH", "/users", jsonContent, `{}`); 400 != httpWriter.Code {
+		t.Error("an optional body that is present must be valid, got", httpWriter.Code)
+	}
+}
+
+// go test -v -test.run TestBodyValidation_RouteOverride ...restful
+func TestBodyValidation_RouteOverride(t *testing.T) {
+	ws := new(WebService).Path("/users")
+	ws.Route(ws.PUT("").Reads(schemaUser{}).BodyValidationEnabled(false).To(dummy).Operation("updateUser"))
+	ws.Route(ws.POST("").Reads(schemaUser{}).BodyValidationEnabled(true).To(dummy).Operation("createUser"))
+	wc := NewContainer()
+	wc.Add(ws)
+	if httpWriter := dispatchRequest(wc, "POST", "/users", jsonContent, `{}`); 400 != httpWriter.Code {
+		t.Error("enabled for the Route, got", httpWriter.Code)
+	}
+	wc.EnableBodyValidation(true)
+	if httpWriter := dispatchRequest(wc, "PUT", "/users", jsonContent, `{}`); 200 != httpWriter.Code {
+		t.Error("disabled for the Route, got", httpWriter.Code)
+	}
+}
+
+// go test -v -test.run TestBodyValidation_PropertyNamesLikeDecoder ...restful
+func TestBodyValidation_PropertyNamesLikeDecoder(t *testing.T) {
+	wc := newBodyValidationContainer(newCreateUserService())
+	httpWriter := dispatchRequest(wc, "POST", "/users", jsonContent, `{"NAME":"John"}`)
+	if 200 != httpWriter.Code || "John" != httpWriter.Body.String() {
+		t.Errorf("case-insensitive property expected, got %d: %s", httpWriter.Code, httpWriter.Body.String())
+	}
+}
+
+// go test -v -test.run TestBodyValidationLimit ...restful
+func TestBodyValidationLimit(t *testing.T) {
+	wc := newBodyValidationContainer(newCreateUserService())
+	wc.BodyValidationLimit(16)
+	if httpWriter := dispatchRequest(wc, "POST", "/users", jsonContent, `{"name":"Johny"}`); 200 != httpWriter.Code {
+		t.Error("a body of exactly the limit is accepted, got", httpWriter.Code)
+	}
+	if httpWriter := dispatchRequest(wc, "POST", "/users", jsonContent, `{"name":"Johnny"}`); 413 != httpWriter.Code {
+		t.Error("413 expected for a body over the limit, got", httpWriter.Code)
+	}
+}
+
+// go test -v -test.run TestBodyValidationLimit_Decompressed ...restful
+func TestBodyValidationLimit_Decompressed(t *testing.T) {
+	var zipped bytes.Buffer
+	gz := gzip.NewWriter(&zipped)
+	gz.Write(bytes.Repeat([]byte(" "), 1024))
+	gz.Write([]byte(`{"name":"Jane"}`))
+	gz.Close()
+	wc := newBodyValidationContainer(newCreateUserService())
+	wc.BodyValidationLimit(512)
+	header := http.Header{"Content-Type": {MIME_JSON}, "Content-Encoding": {ENCODING_GZIP}}
+	if httpWriter := dispatchRequest(wc, "POST", "/users", header, zipped.String()); 413 != httpWriter.Code {
+		t.Error("the limit applies to the decompressed body, got", httpWriter.Code)
+	}
+}
@@ -50,7 +50,13 @@ func (c *CompressingResponseWriter) CloseNotify() <-chan bool {
 }
 
 // Flush is part of http.Flusher interface. Noop if the underlying writer doesn't support it.
+// The pending compressed data is written first such that clients of a stream can decompress what has been written so far.
 func (c *CompressingResponseWriter) Flush() {
+	if compressor, ok := c.compressor.(interface{ Flush() error }); ok {
+		if err := compressor.Flush(); err != nil && trace {
+			traceLogger.Printf("unable to flush the compressor: %v", err)
+		}
+	}
 	flusher, ok := c.writer.(http.Flusher)
 	if !ok {
 		// writer doesn't support http.Flusher interface
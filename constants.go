@@ -10,6 +10,11 @@ const (
 	MIME_ZIP   = "application/zip"          // Accept or Content-Type used in Consumes() and/or Produces()
 	MIME_OCTET = "application/octet-stream" // If Content-Type is not present in request, use the default
 
+	MIME_MULTIPART_FORM = "multipart/form-data"  // Content-Type used in Consumes() for file uploads
+	MIME_NDJSON         = "application/x-ndjson" // Content-Type used in Produces() for streaming newline delimited JSON
+	MIME_JSON_SEQ       = "application/json-seq" // Content-Type used in Produces() for streaming JSON text sequences (RFC 7464)
+	MIME_EVENT_STREAM   = "text/event-stream"    // Content-Type used in Produces() for Server-Sent Events
+
 	HEADER_Allow                         = "Allow"
 	HEADER_Accept                        = "Accept"
 	HEADER_Origin                        = "Origin"
@@ -26,6 +31,11 @@ const (
 	HEADER_AccessControlAllowCredentials = "Access-Control-Allow-Credentials"
 	HEADER_AccessControlAllowHeaders     = "Access-Control-Allow-Headers"
 	HEADER_AccessControlMaxAge           = "Access-Control-Max-Age"
+	HEADER_Location                      = "Location"
+	HEADER_XHTTPMethodOverride           = "X-HTTP-Method-Override"
+	HEADER_XHTTPMethod                   = "X-HTTP-Method"
+	HEADER_LastEventID                   = "Last-Event-ID"
+	HEADER_CacheControl                  = "Cache-Control"
 
 	ENCODING_GZIP    = "gzip"
 	ENCODING_DEFLATE = "deflate"
@@ -20,16 +20,26 @@ import (
 // Container holds a collection of WebServices and a http.ServeMux to dispatch http requests.
 // The requests are further dispatched to routes of WebServices using a RouteSelector
 type Container struct {
-	webServicesLock        sync.RWMutex
-	webServices            []*WebService
-	ServeMux               *http.ServeMux
-	isRegisteredOnRoot     bool
-	containerFilters       []FilterFunction
-	doNotRecover           bool // default is true
-	recoverHandleFunc      RecoverHandleFunction
-	serviceErrorHandleFunc ServiceErrorHandleFunction
-	router                 RouteSelector // default is a CurlyRouter (RouterJSR311 is a slower alternative)
-	contentEncodingEnabled bool          // default is false
+	webServicesLock            sync.RWMutex
+	webServices                []*WebService
+	ServeMux                   *http.ServeMux
+	isRegisteredOnRoot         bool
+	containerFilters           []FilterFunction
+	doNotRecover               bool // default is true
+	recoverHandleFunc          RecoverHandleFunction
+	serviceErrorHandleFunc     ServiceErrorHandleFunction
+	router                     RouteSelector // default is a CurlyRouter (RouterJSR311 is a slower alternative)
+	contentEncodingEnabled     bool          // default is false
+	apiVersionNegotiation      *ApiVersionNegotiation
+	apiVersionGroups           []apiVersionGroup // the WebServices grouped for apiVersionNegotiation
+	pathPolicy                 *PathPolicy
+	autoHeadEnabled            bool // default is false
+	preRoutingFilters          []FilterFunction
+	parameterValidationEnabled bool // default is false
+	bodyValidationEnabled      bool // default is false
+	bodyValidationLimit        int64
+	matrixParametersEnabled    bool                // default is false
+	routeConflictPolicy        RouteConflictPolicy // default is RouteConflictsIgnored
 }
 
 // NewContainer creates a new Container using a new ServeMux and default router (CurlyRouter)
@@ -77,6 +87,9 @@ func (c *Container) DoNotRecover(doNot bool) {
 // Router changes the default Router (currently CurlyRouter)
 func (c *Container) Router(aRouter RouteSelector) {
 	c.router = aRouter
+	c.webServicesLock.RLock()
+	defer c.webServicesLock.RUnlock()
+	c.compileRoutes()
 }
 
 // EnableContentEncoding (default=false) allows for GZIP or DEFLATE encoding of responses.
@@ -95,9 +108,18 @@ func (c *Container) Add(service *WebService) *Container {
 	}
 
 	// cannot have duplicate root paths
-	for _, each := range c.webServices {
-		if each.RootPath() == service.RootPath() {
-			log.Printf("WebService with duplicate root path detected:['%v']", each)
+	if duplicate, ok := c.duplicateRootOf(service); ok {
+		log.Printf("WebService with duplicate root path detected:['%v']", duplicate)
+		os.Exit(1)
+	}
+
+	// report or reject routes that conflict with those already added, if requested
+	if c.routeConflictPolicy != RouteConflictsIgnored {
+		conflicts := c.routeConflictsOf(service)
+		for _, each := range conflicts {
+			log.Printf("route conflict detected: %v", each)
+		}
+		if len(conflicts) > 0 && c.routeConflictPolicy == RouteConflictsRejected {
 			os.Exit(1)
 		}
 	}
@@ -107,9 +129,30 @@ func (c *Container) Add(service *WebService) *Container {
 		c.isRegisteredOnRoot = c.addHandler(service, c.ServeMux)
 	}
 	c.webServices = append(c.webServices, service)
+	c.apiVersionGroups = groupByApiVersion(c.webServices)
+	c.compileRoutes()
 	return c
 }
 
+// duplicateRootOf returns the WebService with the same root path and hosts, and the same API version unless versions are negotiated.
+// this function must run inside the critical region protected by the webServicesLock.
+func (c *Container) duplicateRootOf(service *WebService) (*WebService, bool) {
+	for _, each := range c.webServices {
+		if each.hasSameRoot(service) || c.apiVersionNegotiation == nil && each.hasSameRootAndHosts(service) {
+			return each, true
+		}
+	}
+	return nil, false
+}
+
+// compileRoutes lets the router precompile the routes of all WebServices, if it supports that.
+// this function must run inside the critical region protected by the webServicesLock.
+func (c *Container) compileRoutes() {
+	if compiler, ok := c.router.(compilingRouteSelector); ok {
+		compiler.compile(c.webServices)
+	}
+}
+
 // addHandler may set a new HandleFunc for the serveMux
 // this function must run inside the critical region protected by the webServicesLock.
 // returns true if the function was registered on root ("/")
@@ -150,7 +193,7 @@ func (c *Container) Remove(ws *WebService) error {
 	newServices := []*WebService{}
 	newIsRegisteredOnRoot := false
 	for _, each := range c.webServices {
-		if each.rootPath != ws.rootPath {
+		if !each.hasSameRoot(ws) {
 			// If not registered on root then add specific mapping
 			if !newIsRegisteredOnRoot {
 				newIsRegisteredOnRoot = c.addHandler(each, newServeMux)
@@ -159,6 +202,8 @@ func (c *Container) Remove(ws *WebService) error {
 		}
 	}
 	c.webServices, c.ServeMux, c.isRegisteredOnRoot = newServices, newServeMux, newIsRegisteredOnRoot
+	c.apiVersionGroups = groupByApiVersion(c.webServices)
+	c.compileRoutes()
 	return nil
 }
 
@@ -206,6 +251,29 @@ func (c *Container) Dispatch(httpWriter http.ResponseWriter, httpRequest *http.R
 
 // Dispatch the incoming Http Request to a matching WebService.
 func (c *Container) dispatch(httpWriter http.ResponseWriter, httpRequest *http.Request) {
+	if len(c.preRoutingFilters) == 0 {
+		c.dispatchRoute(httpWriter, httpRequest, nil)
+		return
+	}
+	// Instal panic recovery unless told otherwise
+	if !c.doNotRecover { // catch all for 500 response
+		defer func() {
+			if r := recover(); r != nil {
+				c.recoverHandleFunc(r, httpWriter)
+				return
+			}
+		}()
+	}
+	chain := FilterChain{Filters: c.preRoutingFilters, Target: func(req *Request, resp *Response) {
+		// select the Route for the (possibly modified) request
+		c.dispatchRoute(resp.ResponseWriter, req.Request, req.attributes)
+	}}
+	chain.ProcessFilter(NewRequest(httpRequest), NewResponse(httpWriter))
+}
+
+// dispatchRoute selects the Route for the Http Request and calls it, passing through all filters.
+// The attributes, if not nil, are those of the Request passed to the pre-routing filters.
+func (c *Container) dispatchRoute(httpWriter http.ResponseWriter, httpRequest *http.Request, attributes map[string]interface{}) {
 	// so we can assign a compressing one later
 	writer := httpWriter
 
@@ -220,6 +288,11 @@ func (c *Container) dispatch(httpWriter http.ResponseWriter, httpRequest *http.R
 	if !c.doNotRecover { // catch all for 500 response
 		defer func() {
 			if r := recover(); r != nil {
+				if headWriter, ok := writer.(*headResponseWriter); ok {
+					// the GET Route did not finish ; the recover handler determines the response
+					headWriter.statusCode, headWriter.contentLength = 0, 0
+					defer headWriter.finish()
+				}
 				c.recoverHandleFunc(r, writer)
 				return
 			}
@@ -229,14 +302,35 @@ func (c *Container) dispatch(httpWriter http.ResponseWriter, httpRequest *http.R
 	// Find best match Route ; err is non nil if no match was found
 	var webService *WebService
 	var route *Route
+	var routePath string
+	var headForGet bool
 	var err error
 	func() {
 		c.webServicesLock.RLock()
 		defer c.webServicesLock.RUnlock()
-		webService, route, err = c.router.SelectRoute(
-			c.webServices,
-			httpRequest)
+		webServices := c.webServices
+		routingRequest := httpRequest
+		if c.apiVersionNegotiation != nil {
+			webServices, err = c.apiVersionNegotiation.selectWebServices(c.apiVersionGroups, httpRequest)
+			if err != nil {
+				return
+			}
+			routingRequest = c.apiVersionNegotiation.routingRequest(httpRequest)
+		}
+		webService, route, routePath, err = c.selectRoute(webServices, routingRequest)
+		if err != nil {
+			if ws, rt, rp, ok := c.selectGETRouteForHEAD(webServices, routingRequest, err); ok {
+				webService, route, routePath, err, headForGet = ws, rt, rp, nil, true
+			}
+		}
 	}()
+	if ser, ok := err.(ServiceError); ok && ser.Code == http.StatusMethodNotAllowed {
+		// the Allow header lists the methods of all WebServices that match the path
+		if methods := c.computeAllowedMethods(NewRequest(httpRequest)); len(methods) > 0 {
+			ser.Header = http.Header{HEADER_Allow: []string{strings.Join(methods, ", ")}}
+			err = ser
+		}
+	}
 	if err != nil {
 		// a non-200 response (may be compressed) has already been written
 		// run container filters anyway ; they should not touch the response...
@@ -248,12 +342,17 @@ func (c *Container) dispatch(httpWriter http.ResponseWriter, httpRequest *http.R
 			}
 			// TODO
 		}}
-		chain.ProcessFilter(NewRequest(httpRequest), NewResponse(writer))
+		req := NewRequest(httpRequest)
+		if attributes != nil {
+			req.attributes = attributes
+		}
+		chain.ProcessFilter(req, NewResponse(writer))
 		return
 	}
 
 	// Unless httpWriter is already an CompressingResponseWriter see if we need to install one
-	if _, isCompressing := httpWriter.(*CompressingResponseWriter); !isCompressing {
+	// A HEAD request served by a GET Route has no body to compress
+	if _, isCompressing := httpWriter.(*CompressingResponseWriter); !isCompressing && !headForGet {
 		// Detect if compression is needed
 		// assume without compression, test for override
 		contentEncodingEnabled := c.contentEncodingEnabled
@@ -274,12 +373,52 @@ func (c *Container) dispatch(httpWriter http.ResponseWriter, httpRequest *http.R
 		}
 	}
 
+	// the header of a HEAD response is written when the Route returns, or after recovering if it panics
+	routeReturned := false
+	if headForGet {
+		headWriter := &headResponseWriter{ResponseWriter: writer}
+		defer func() {
+			if routeReturned {
+				headWriter.finish()
+			}
+		}()
+		writer = headWriter
+	}
+
 	pathProcessor, routerProcessesPath := c.router.(PathProcessor)
 	if !routerProcessesPath {
 		pathProcessor = defaultPathProcessor{}
 	}
-	pathParams := pathProcessor.ExtractParameters(route, webService, httpRequest.URL.Path)
+	pathParams := pathProcessor.ExtractParameters(route, webService, routePath)
+	if c.pathPolicy != nil {
+		c.pathPolicy.unescapeParameters(pathParams)
+	}
+	if hostParams, ok := webService.hostParameters(httpRequest.Host); ok {
+		for name, value := range hostParams {
+			if _, exists := pathParams[name]; !exists {
+				pathParams[name] = value
+			}
+		}
+	}
 	wrappedRequest, wrappedResponse := route.wrapRequestResponse(writer, httpRequest, pathParams)
+	// stop any keep-alive of event streams before the response is finished
+	defer wrappedResponse.closeEventStreams()
+	if attributes != nil {
+		wrappedRequest.attributes = attributes
+	}
+	if c.matrixParametersEnabled {
+		wrappedRequest.matrixParameters = c.matrixParametersOf(httpRequest)
+	}
+	target := route.Function
+	if c.bodyValidationEnabledFor(route) {
+		target = c.validatingBody(route, target)
+	}
+	if c.parameterValidationEnabledFor(route) {
+		target = c.validatingParameters(route, target)
+	}
+	if query, header := strictParametersFor(webService, route); query || header {
+		target = c.rejectingUnknownParameters(route, query, header, target)
+	}
 	// pass through filters (if any)
 	if size := len(c.containerFilters) + len(webService.filters) + len(route.Filters); size > 0 {
 		// compose filter chain
@@ -289,15 +428,28 @@ func (c *Container) dispatch(httpWriter http.ResponseWriter, httpRequest *http.R
 		allFilters = append(allFilters, route.Filters...)
 		chain := FilterChain{
 			Filters:       allFilters,
-			Target:        route.Function,
+			Target:        target,
 			ParameterDocs: route.ParameterDocs,
 			Operation:     route.Operation,
 		}
 		chain.ProcessFilter(wrappedRequest, wrappedResponse)
 	} else {
 		// no filters, handle request by route
-		route.Function(wrappedRequest, wrappedResponse)
+		target(wrappedRequest, wrappedResponse)
 	}
+	routeReturned = true
+}
+
+// selectRoute lets the router select a Route, using the PathPolicy if set.
+// It returns the path that was used to select the Route.
+// this function must run inside the critical region protected by the webServicesLock.
+func (c *Container) selectRoute(webServices []*WebService, httpRequest *http.Request) (*WebService, *Route, string, error) {
+	httpRequest = c.withoutMatrixParameters(httpRequest)
+	if c.pathPolicy != nil {
+		return c.pathPolicy.selectRoute(c.router, webServices, httpRequest)
+	}
+	webService, route, err := c.router.SelectRoute(webServices, httpRequest)
+	return webService, route, httpRequest.URL.Path, err
 }
 
 // fixedPrefixPath returns the fixed part of the partspec ; it may include template vars {}
@@ -313,14 +465,14 @@ func fixedPrefixPath(pathspec string) string {
 func (c *Container) ServeHTTP(httpWriter http.ResponseWriter, httpRequest *http.Request) {
 	// Skip, if content encoding is disabled
 	if !c.contentEncodingEnabled {
-		c.ServeMux.ServeHTTP(httpWriter, httpRequest)
+		c.serveMux(httpWriter, httpRequest)
 		return
 	}
 	// content encoding is enabled
 
 	// Skip, if httpWriter is already an CompressingResponseWriter
 	if _, ok := httpWriter.(*CompressingResponseWriter); ok {
-		c.ServeMux.ServeHTTP(httpWriter, httpRequest)
+		c.serveMux(httpWriter, httpRequest)
 		return
 	}
 
@@ -343,7 +495,18 @@ func (c *Container) ServeHTTP(httpWriter http.ResponseWriter, httpRequest *http.
 		}
 	}
 
-	c.ServeMux.ServeHTTP(writer, httpRequest)
+	c.serveMux(writer, httpRequest)
+}
+
+// serveMux lets the ServeMux dispatch the request. If matrix parameters are enabled then the handler is selected
+// using the path without them, such that e.g. /cars;color=red/beetle is dispatched to the Container of the WebService /cars.
+func (c *Container) serveMux(httpWriter http.ResponseWriter, httpRequest *http.Request) {
+	if routingRequest := c.withoutMatrixParameters(httpRequest); routingRequest != httpRequest {
+		handler, _ := c.ServeMux.Handler(routingRequest)
+		handler.ServeHTTP(httpWriter, httpRequest)
+		return
+	}
+	c.ServeMux.ServeHTTP(httpWriter, httpRequest)
 }
 
 // Handle registers the handler for the given pattern. If a handler already exists for pattern, Handle panics.
@@ -406,6 +569,16 @@ func (c *Container) Filter(filter FilterFunction) {
 	c.containerFilters = append(c.containerFilters, filter)
 }
 
+// PreRoutingFilter appends a container FilterFunction that is called before a Route is selected.
+// Such a filter can modify the http.Request of the Request (e.g. its URL path, method or headers)
+// after which the Route is selected for the modified request. Its attributes are kept.
+// It can also write a response without calling the chain, in which case no Route is selected.
+// Path parameters and the selected Route are not available to these filters.
+// Note that the http.ServeMux of the Container has already matched the original request path.
+func (c *Container) PreRoutingFilter(filter FilterFunction) {
+	c.preRoutingFilters = append(c.preRoutingFilters, filter)
+}
+
 // RegisteredWebServices returns the collections of added WebServices
 func (c *Container) RegisteredWebServices() []*WebService {
 	c.webServicesLock.RLock()
@@ -417,26 +590,89 @@ func (c *Container) RegisteredWebServices() []*WebService {
 	return result
 }
 
+// RouteConflicts returns the conflicts between the Routes of all its WebServices.
+func (c *Container) RouteConflicts() []RouteConflict {
+	conflicts := []RouteConflict{}
+	services := c.RegisteredWebServices()
+	for i, each := range services {
+		conflicts = append(conflicts, each.RouteConflicts()...)
+		for _, other := range services[:i] {
+			conflicts = append(conflicts, each.routeConflictsWithService(other)...)
+		}
+	}
+	return conflicts
+}
+
+// routeConflictsOf returns the conflicts among the Routes of the service and with those of the WebServices already added.
+// this function must run inside the critical region protected by the webServicesLock.
+func (c *Container) routeConflictsOf(service *WebService) []RouteConflict {
+	conflicts := service.RouteConflicts()
+	for _, each := range c.webServices {
+		conflicts = append(conflicts, service.routeConflictsWithService(each)...)
+	}
+	return conflicts
+}
+
+// CheckRouteConflicts returns a RouteConflictError if any of the Routes of its WebServices conflict.
+// Use this in a test or at startup to prevent serving ambiguous Routes.
+func (c *Container) CheckRouteConflicts() error {
+	if conflicts := c.RouteConflicts(); len(conflicts) > 0 {
+		return RouteConflictError{Conflicts: conflicts}
+	}
+	return nil
+}
+
 // computeAllowedMethods returns a list of HTTP methods that are valid for a Request
 func (c *Container) computeAllowedMethods(req *Request) []string {
+	httpRequest := c.withoutMatrixParameters(req.Request)
 	// Go through all RegisteredWebServices() and all its Routes to collect the options
-	methods := []string{}
-	requestPath := req.Request.URL.Path
-	for _, ws := range c.RegisteredWebServices() {
+	webServices := c.RegisteredWebServices()
+	if c.apiVersionNegotiation != nil {
+		c.webServicesLock.RLock()
+		groups := c.apiVersionGroups
+		c.webServicesLock.RUnlock()
+		if selected, err := c.apiVersionNegotiation.selectWebServices(groups, httpRequest); err == nil {
+			webServices = selected
+		}
+	}
+	webServices = selectWebServicesByHost(webServices, httpRequest.Host)
+	if c.pathPolicy == nil {
+		return c.allowedMethodsForPath(webServices, httpRequest, httpRequest.URL.Path)
+	}
+	// use the first path for which methods are found, in the same order as used for selecting a Route
+	for _, each := range c.pathPolicy.candidatePaths(webServices, c.pathPolicy.requestPath(httpRequest)) {
+		if methods := c.allowedMethodsForPath(webServices, httpRequest, each); len(methods) > 0 {
+			return methods
+		}
+	}
+	return []string{}
+}
+
+// allowedMethodsForPath returns the distinct HTTP methods of all Routes that match the request path and pass their conditions
+func (c *Container) allowedMethodsForPath(webServices []*WebService, httpRequest *http.Request, requestPath string) []string {
+	routes := []*Route{}
+	autoHead := false
+	for _, ws := range webServices {
 		matches := ws.pathExpr.Matcher.FindStringSubmatch(requestPath)
 		if matches != nil {
 			finalMatch := matches[len(matches)-1]
-			for _, rt := range ws.Routes() {
+			wsRoutes := ws.Routes()
+			for i, rt := range wsRoutes {
 				matches := rt.pathExpr.Matcher.FindStringSubmatch(finalMatch)
 				if matches != nil {
 					lastMatch := matches[len(matches)-1]
-					if lastMatch == "" || lastMatch == "/" { // do not include if value is neither empty nor ‘/’.
-						methods = append(methods, rt.Method)
+					if (lastMatch == "" || lastMatch == "/") && rt.passesConditions(httpRequest) { // do not include if value is neither empty nor ‘/’.
+						routes = append(routes, &wsRoutes[i])
+						autoHead = autoHead || (rt.Method == http.MethodGet && c.autoHeadEnabledFor(ws))
 					}
 				}
 			}
 		}
 	}
+	methods := allowedMethods(routes)
+	if autoHead && !containsMethod(methods, http.MethodHead) {
+		methods = append(methods, http.MethodHead)
+	}
 	// methods = append(methods, "OPTIONS")  not sure about this
 	return methods
 }
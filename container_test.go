@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -171,3 +172,86 @@ func TestContainerCompressResponse(t *testing.T) {
 		}
 	}
 }
+
+// go test -v -test.run TestContainer_MethodNotAllowed ...restful
+func TestContainer_MethodNotAllowed(t *testing.T) {
+	routers := map[string]RouteSelector{"curly": CurlyRouter{}, "jsr311": RouterJSR311{}, "radix": NewRadixRouter()}
+	for name, router := range routers {
+		wc := NewContainer()
+		wc.Router(router)
+		var allowed []string
+		wc.ServiceErrorHandler(func(err ServiceError, req *Request, resp *Response) {
+			allowed = err.AllowedMethods()
+			writeServiceError(err, req, resp)
+		})
+		ws := new(WebService).Path("/users")
+		ws.Route(ws.GET("/{id}").If(func(*http.Request) bool { return false }).To(dummy))
+		ws.Route(ws.PUT("/{id}").To(dummy))
+		ws.Route(ws.PUT("/{id}").Consumes(MIME_XML).To(dummy))
+		ws.Route(ws.DELETE("/{id}").To(dummy))
+		wc.Add(ws)
+		admin := new(WebService).Path("/admin")
+		admin.Route(admin.GET("/{id}").If(func(*http.Request) bool { return false }).To(dummy))
+		wc.Add(admin)
+
+		for _, each := range []struct {
+			method, path string
+			code         int
+			allow        string
+			allowed      int
+		}{
+			{"POST", "/users/42", http.StatusMethodNotAllowed, "PUT, DELETE", 2},
+			{"GET", "/users/42", http.StatusMethodNotAllowed, "PUT, DELETE", 2}, // the only GET Route does not pass its condition
+			{"GET", "/admin/42", http.StatusNotFound, "", 0},                    // no Route passes its condition
+			{"POST", "/users/42/orders", http.StatusNotFound, "", 0},
+			{"POST", "/orders/42", http.StatusNotFound, "", 0},
+		} {
+			allowed = nil
+			httpRequest, _ := http.NewRequest(each.method, each.path, nil)
+			httpWriter := httptest.NewRecorder()
+			wc.Dispatch(httpWriter, httpRequest)
+			if got, want := httpWriter.Code, each.code; got != want {
+				t.Errorf("[%s] %s %s got %v want %v", name, each.method, each.path, got, want)
+			}
+			if each.code != http.StatusMethodNotAllowed {
+				if len(allowed) != 0 {
+					t.Errorf("[%s] unexpected allowed methods %v", name, allowed)
+				}
+				continue
+			}
+			if got, want := httpWriter.Header().Get(HEADER_Allow), each.allow; got != want {
+				t.Errorf("[%s] %s %s got %v want %v", name, each.method, each.path, got, want)
+			}
+			if got, want := len(allowed), each.allowed; got != want {
+				t.Errorf("[%s] got %v want %v", name, allowed, want)
+			}
+		}
+	}
+}
+
+// go test -v -test.run TestContainer_NotFoundIfNoRoutePassesConditions ...restful
+func TestContainer_NotFoundIfNoRoutePassesConditions(t *testing.T) {
+	wc := NewContainer()
+	ws := new(WebService).Path("/users")
+	ws.Route(ws.GET("/{id}").If(func(*http.Request) bool { return false }).To(dummy))
+	wc.Add(ws)
+	httpWriter := dispatchRequest(wc, "GET", "/users/42", nil, "")
+	if 404 != httpWriter.Code {
+		t.Error("404 expected if the only Route does not pass its condition, got", httpWriter.Code)
+	}
+	if _, ok := httpWriter.Header()[HEADER_Allow]; ok {
+		t.Error("no Allow header expected, got", httpWriter.Header().Get(HEADER_Allow))
+	}
+}
+
+// dispatchRequest lets the Container dispatch a request, without its ServeMux, and returns the recorded response.
+// The header and body are optional.
+func dispatchRequest(wc *Container, method, url string, header http.Header, body string) *httptest.ResponseRecorder {
+	httpRequest := httptest.NewRequest(method, url, strings.NewReader(body))
+	for name, values := range header {
+		httpRequest.Header[name] = values
+	}
+	httpWriter := httptest.NewRecorder()
+	wc.Dispatch(httpWriter, httpRequest)
+	return httpWriter
+}
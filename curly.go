@@ -22,6 +22,7 @@ func (c CurlyRouter) SelectRoute(
 
 	requestTokens := tokenizePath(httpRequest.URL.Path)
 
+	webServices = selectWebServicesByHost(webServices, httpRequest.Host)
 	detectedService := c.detectWebService(requestTokens, webServices)
 	if detectedService == nil {
 		if trace {
@@ -105,6 +106,7 @@ func (c CurlyRouter) matchesRouteByPathTokens(routeTokens, requestTokens []strin
 
 // regularMatchesPathToken tests whether the regular expression part of routeToken matches the requestToken or all remaining tokens
 // format routeToken is {someVar:someExpression}, e.g. {zipcode:[\d][\d][\d][\d][A-Z][A-Z]}
+// or {someVar:someConstraint}, e.g. {id:int}, see RegisterPathParameterConstraint
 func (c CurlyRouter) regularMatchesPathToken(routeToken string, colon int, requestToken string) (matchesToken bool, matchesRemainder bool) {
 	regPart := routeToken[colon+1 : len(routeToken)-1]
 	if regPart == "*" {
@@ -113,6 +115,9 @@ func (c CurlyRouter) regularMatchesPathToken(routeToken string, colon int, reque
 		}
 		return true, true
 	}
+	if constraint, ok := pathParameterConstraintNamed(regPart); ok {
+		return constraint.matcher.MatchString(requestToken), false
+	}
 	matched, err := regexp.MatchString(regPart, requestToken)
 	return (matched && err == nil), false
 }
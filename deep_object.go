@@ -0,0 +1,299 @@
+package restful
+
+// Copyright 2024 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// DeepObjectQueryParameter returns the values of a Query parameter that uses bracket notation (OpenAPI style deepObject) as nested maps.
+// Each value is a string, a []string (for repeated or [] keys) or a map[string]interface{}, e.g.
+//
+//	?filter[status]=open&filter[owner][id]=7&filter[tags][]=a&filter[tags][]=b
+//	=> map[owner:map[id:7] status:open tags:[a b]]
+//
+// Returns an empty map if the parameter is absent or a ServiceError with status 400 (Bad Request) if a key is malformed.
+// Document such parameter using Style(StyleDeepObject).
+func (r *Request) DeepObjectQueryParameter(name string) (map[string]interface{}, error) {
+	value, problems := r.deepObjectQueryParameter(name)
+	if len(problems) > 0 {
+		return nil, newInvalidParametersError(problems)
+	}
+	return value, nil
+}
+
+// deepObjectQueryParameter returns the nested maps of the parameter or the problems with its keys.
+// Only the keys of the parameter are inspected, i.e. the name itself and keys starting with name[.
+func (r *Request) deepObjectQueryParameter(name string) (map[string]interface{}, []string) {
+	all, problems := r.bracketQueryParameters(name)
+	if len(problems) > 0 {
+		return nil, problems
+	}
+	switch value := all[name].(type) {
+	case nil:
+		return map[string]interface{}{}, nil
+	case map[string]interface{}:
+		return value, nil
+	}
+	return nil, []string{fmt.Sprintf("query parameter %s: expected keys in brackets, e.g. %s[key]=value", name, name)}
+}
+
+// ReadDeepObjectQueryParameters decodes all Query parameters, including those that use bracket notation, into the struct pointed to by v.
+// Fields are matched using the query tag, or else the json tag, or else the field name. Nested structs and maps with string keys
+// decode keys in brackets, slices decode repeated keys and keys ending with [], e.g.
+//
+//	type listIssues struct {
+//		Filter struct {
+//			Status string `query:"status"`
+//			Owner  struct {
+//				ID int `query:"id"`
+//			} `query:"owner"`
+//		} `query:"filter"`
+//		Sort []string `query:"sort"` // sort[]=name&sort[]=created
+//	}
+//
+// Unknown keys are ignored, also if malformed. Returns a ServiceError with status 400 (Bad Request) that lists every malformed key
+// of a field or value that could not be converted.
+func (r *Request) ReadDeepObjectQueryParameters(v interface{}) error {
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Ptr || target.IsNil() || target.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("ReadDeepObjectQueryParameters requires a non-nil pointer to a struct, got %T", v)
+	}
+	all, problems := r.bracketQueryParameters(bracketFieldNames(target.Elem().Type())...)
+	if len(problems) > 0 {
+		return newInvalidParametersError(problems)
+	}
+	decodeBracketValue(target.Elem(), all, "", &problems)
+	if len(problems) > 0 {
+		return newInvalidParametersError(problems)
+	}
+	return nil
+}
+
+// bracketQueryParameters returns the Query parameters with one of the names as nested maps or the problems with their keys.
+// Keys of other parameters are not inspected.
+func (r *Request) bracketQueryParameters(names ...string) (map[string]interface{}, []string) {
+	query := r.Request.URL.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		name := key
+		if open := strings.Index(key, "["); open != -1 {
+			name = key[:open]
+		}
+		for _, each := range names {
+			if name == each {
+				keys = append(keys, key)
+				break
+			}
+		}
+	}
+	sort.Strings(keys)
+	root := map[string]interface{}{}
+	problems := []string{}
+	for _, key := range keys {
+		if problem := insertBracketValue(root, key, query[key]); len(problem) > 0 {
+			problems = append(problems, fmt.Sprintf("query parameter %s: %s", key, problem))
+		}
+	}
+	if len(problems) > 0 {
+		return nil, problems
+	}
+	return root, nil
+}
+
+// splitBracketKey returns the name and the keys in brackets, e.g. filter[owner][id] => [filter owner id].
+// An empty key, e.g. sort[], is only allowed at the end.
+func splitBracketKey(key string) ([]string, string) {
+	open := strings.Index(key, "[")
+	if open == -1 {
+		if strings.Contains(key, "]") {
+			return nil, "malformed key, unexpected ]"
+		}
+		return []string{key}, ""
+	}
+	if open == 0 {
+		return nil, "malformed key, missing name before ["
+	}
+	segments := []string{key[:open]}
+	rest := key[open:]
+	for len(rest) > 0 {
+		if rest[0] != '[' {
+			return nil, "malformed key, expected [ after ]"
+		}
+		end := strings.Index(rest, "]")
+		if end == -1 {
+			return nil, "malformed key, missing ]"
+		}
+		segment := rest[1:end]
+		if strings.Contains(segment, "[") {
+			return nil, "malformed key, nested ["
+		}
+		if len(segment) == 0 && end+1 != len(rest) {
+			return nil, "malformed key, [] must be at the end"
+		}
+		segments = append(segments, segment)
+		rest = rest[end+1:]
+	}
+	return segments, ""
+}
+
+// insertBracketValue adds the values of the key to the nested maps or returns a problem.
+func insertBracketValue(root map[string]interface{}, key string, values []string) string {
+	segments, problem := splitBracketKey(key)
+	if len(problem) > 0 {
+		return problem
+	}
+	isArray := segments[len(segments)-1] == ""
+	if isArray {
+		segments = segments[:len(segments)-1]
+	}
+	parent := root
+	for _, each := range segments[:len(segments)-1] {
+		switch existing := parent[each].(type) {
+		case nil:
+			child := map[string]interface{}{}
+			parent[each] = child
+			parent = child
+		case map[string]interface{}:
+			parent = existing
+		default:
+			return fmt.Sprintf("conflicting keys, %s is not an object", each)
+		}
+	}
+	last := segments[len(segments)-1]
+	switch existing := parent[last].(type) {
+	case nil:
+		if isArray || len(values) > 1 {
+			parent[last] = append([]string{}, values...)
+		} else {
+			parent[last] = values[0]
+		}
+	case string:
+		parent[last] = append([]string{existing}, values...)
+	case []string:
+		parent[last] = append(existing, values...)
+	default:
+		return fmt.Sprintf("conflicting keys, %s is an object", last)
+	}
+	return ""
+}
+
+// decodeBracketValue assigns the (nested) data to the value and collects the problems using the bracket path of the value.
+func decodeBracketValue(value reflect.Value, data interface{}, path string, problems *[]string) {
+	if value.Kind() == reflect.Ptr {
+		pointer := reflect.New(value.Type().Elem())
+		decodeBracketValue(pointer.Elem(), data, path, problems)
+		value.Set(pointer)
+		return
+	}
+	isText := reflect.PtrTo(value.Type()).Implements(textUnmarshalerType)
+	switch data := data.(type) {
+	case map[string]interface{}:
+		switch {
+		case value.Kind() == reflect.Struct && !isText:
+			decodeBracketStruct(value, data, path, problems)
+		case value.Kind() == reflect.Map && value.Type().Key().Kind() == reflect.String:
+			if value.IsNil() {
+				value.Set(reflect.MakeMap(value.Type()))
+			}
+			for key, each := range data {
+				entry := reflect.New(value.Type().Elem()).Elem()
+				decodeBracketValue(entry, each, bracketPath(path, key), problems)
+				value.SetMapIndex(reflect.ValueOf(key).Convert(value.Type().Key()), entry)
+			}
+		default:
+			*problems = append(*problems, fmt.Sprintf("query parameter %s: unexpected keys in brackets", path))
+		}
+	case string:
+		decodeBracketValue(value, []string{data}, path, problems)
+	case []string:
+		if value.Kind() == reflect.Slice && !isText {
+			slice := reflect.MakeSlice(value.Type(), len(data), len(data))
+			for i, each := range data {
+				if err := setSingleValue(slice.Index(i), each, ""); err != nil {
+					*problems = append(*problems, fmt.Sprintf("query parameter %s: %v", path, err))
+				}
+			}
+			value.Set(slice)
+			return
+		}
+		if len(data) > 1 {
+			*problems = append(*problems, fmt.Sprintf("query parameter %s: multiple values are not allowed, got %d", path, len(data)))
+			return
+		}
+		if !isBindableType(value.Type()) {
+			*problems = append(*problems, fmt.Sprintf("query parameter %s: expected keys in brackets", path))
+			return
+		}
+		if err := setSingleValue(value, data[0], ""); err != nil {
+			*problems = append(*problems, fmt.Sprintf("query parameter %s: %v", path, err))
+		}
+	}
+}
+
+// decodeBracketStruct assigns the entries of the data to the matching fields of the struct value.
+func decodeBracketStruct(value reflect.Value, data map[string]interface{}, path string, problems *[]string) {
+	structType := value.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if len(field.PkgPath) > 0 {
+			continue
+		}
+		name := bracketFieldName(field)
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && len(field.Tag.Get("query")) == 0 {
+			decodeBracketStruct(value.Field(i), data, path, problems)
+			continue
+		}
+		if each, ok := data[name]; ok {
+			decodeBracketValue(value.Field(i), each, bracketPath(path, name), problems)
+		}
+	}
+}
+
+// bracketFieldNames returns the names of the parameters that decodeBracketStruct assigns to the fields of the struct type.
+func bracketFieldNames(structType reflect.Type) []string {
+	names := []string{}
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if len(field.PkgPath) > 0 {
+			continue
+		}
+		name := bracketFieldName(field)
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && len(field.Tag.Get("query")) == 0 {
+			names = append(names, bracketFieldNames(field.Type)...)
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// bracketFieldName returns the name of the field using the query tag, the json tag or the field name.
+func bracketFieldName(field reflect.StructField) string {
+	if name := field.Tag.Get("query"); len(name) > 0 {
+		return name
+	}
+	if name := strings.Split(field.Tag.Get("json"), ",")[0]; len(name) > 0 {
+		return name
+	}
+	return field.Name
+}
+
+// bracketPath returns the key in bracket notation, e.g. filter[owner] + id => filter[owner][id]
+func bracketPath(path, key string) string {
+	if len(path) == 0 {
+		return key
+	}
+	return path + "[" + key + "]"
+}
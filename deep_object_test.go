@@ -0,0 +1,154 @@
+package restful
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// go test -v -test.run TestDeepObjectQueryParameter ...restful
+func TestDeepObjectQueryParameter(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "/issues?filter[status]=open&filter[owner][id]=7&filter[tags][]=a&filter[tags][]=b&sort[]=name&limit=10", nil)
+	request := NewRequest(httpRequest)
+	filter, err := request.DeepObjectQueryParameter("filter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"status": "open",
+		"owner":  map[string]interface{}{"id": "7"},
+		"tags":   []string{"a", "b"},
+	}
+	if !reflect.DeepEqual(filter, want) {
+		t.Errorf("got %v want %v", filter, want)
+	}
+	if missing, err := request.DeepObjectQueryParameter("missing"); err != nil || len(missing) != 0 {
+		t.Errorf("got %v,%v want empty map", missing, err)
+	}
+	if _, err := request.DeepObjectQueryParameter("limit"); err == nil {
+		t.Error("expected error for parameter without brackets")
+	}
+}
+
+// go test -v -test.run TestDeepObjectQueryParameter_Malformed ...restful
+func TestDeepObjectQueryParameter_Malformed(t *testing.T) {
+	for _, query := range []string{
+		"filter[status=open",
+		"filter[a][]b=1",
+		"filter[][a]=1",
+		"filter[a]x=1",
+		"filter=1&filter[a]=2",
+		"filter[a]=1&filter[a][b]=2",
+	} {
+		httpRequest, _ := http.NewRequest("GET", "/issues?"+query, nil)
+		_, err := NewRequest(httpRequest).DeepObjectQueryParameter("filter")
+		ser, ok := err.(ServiceError)
+		if !ok || ser.Code != http.StatusBadRequest {
+			t.Errorf("%s: got %v want 400 ServiceError", query, err)
+		}
+	}
+}
+
+// go test -v -test.run TestDeepObjectQueryParameter_OtherKeys ...restful
+func TestDeepObjectQueryParameter_OtherKeys(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "/issues?filter[status]=open&a]=1&[status]=x&filters[a]=2", nil)
+	filter, err := NewRequest(httpRequest).DeepObjectQueryParameter("filter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fmt.Sprint(filter), "map[status:open]"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+type issueFilter struct {
+	Status string `query:"status"`
+	Owner  struct {
+		ID int `query:"id"`
+	} `query:"owner"`
+	Tags   []string          `json:"tags"`
+	Labels map[string]string `query:"labels"`
+}
+
+type listIssuesParams struct {
+	Filter *issueFilter `query:"filter"`
+	Sort   []string     `query:"sort"`
+	Limit  int          `query:"limit"`
+}
+
+// go test -v -test.run TestReadDeepObjectQueryParameters ...restful
+func TestReadDeepObjectQueryParameters(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "/issues?filter[status]=open&filter[owner][id]=7&filter[tags][]=a&filter[labels][team]=core&sort[]=name&sort[]=created&limit=10&unknown[x]=1", nil)
+	params := new(listIssuesParams)
+	if err := NewRequest(httpRequest).ReadDeepObjectQueryParameters(params); err != nil {
+		t.Fatal(err)
+	}
+	if params.Filter == nil || params.Filter.Status != "open" || params.Filter.Owner.ID != 7 {
+		t.Fatalf("unexpected filter %#v", params.Filter)
+	}
+	if got, want := params.Filter.Tags, []string{"a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := params.Filter.Labels, map[string]string{"team": "core"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := params.Sort, []string{"name", "created"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := params.Limit, 10; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestReadDeepObjectQueryParameters_Errors ...restful
+func TestReadDeepObjectQueryParameters_Errors(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "/issues?filter[owner][id]=x&filter[status][a]=1&limit=1&limit=2", nil)
+	err := NewRequest(httpRequest).ReadDeepObjectQueryParameters(new(listIssuesParams))
+	ser, ok := err.(ServiceError)
+	if !ok || ser.Code != http.StatusBadRequest {
+		t.Fatalf("got %v want 400 ServiceError", err)
+	}
+	for _, each := range []string{
+		`query parameter filter[owner][id]: invalid integer "x"`,
+		`query parameter filter[status]: unexpected keys in brackets`,
+		`query parameter limit: multiple values are not allowed, got 2`,
+	} {
+		if !strings.Contains(ser.Message, each) {
+			t.Errorf("expected %q in %q", each, ser.Message)
+		}
+	}
+}
+
+// go test -v -test.run TestReadDeepObjectQueryParameters_MalformedUnknownKeys ...restful
+func TestReadDeepObjectQueryParameters_MalformedUnknownKeys(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "/issues?limit=3&other[x=1&[y]=2&z]=3&filters[a=4", nil)
+	params := new(listIssuesParams)
+	if err := NewRequest(httpRequest).ReadDeepObjectQueryParameters(params); err != nil {
+		t.Fatal("malformed keys of unknown parameters must be ignored, got", err)
+	}
+	if got, want := params.Limit, 3; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	httpRequest, _ = http.NewRequest("GET", "/issues?filter[status=open&other[x=1", nil)
+	err := NewRequest(httpRequest).ReadDeepObjectQueryParameters(params)
+	ser, ok := err.(ServiceError)
+	if !ok || ser.Code != http.StatusBadRequest {
+		t.Fatalf("got %v want 400 ServiceError", err)
+	}
+	if strings.Contains(ser.Message, "other") || !strings.Contains(ser.Message, "filter[status") {
+		t.Error("only the malformed key of a field expected, got", ser.Message)
+	}
+}
+
+// go test -v -test.run TestParameterStyle ...restful
+func TestParameterStyle(t *testing.T) {
+	p := QueryParameter("filter", "").Style(StyleDeepObject).Explode(true)
+	if got, want := p.Data().Style, "deepObject"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if p.Data().Explode == nil || !*p.Data().Explode {
+		t.Error("expected explode")
+	}
+}
@@ -35,7 +35,12 @@ Regular expression matching Routes
 A Route parameter can be specified using the format "uri/{var[:regexp]}" or the special version "uri/{var:*}" for matching the tail of the path.
 For example, /persons/{name:[A-Z][A-Z]} can be used to restrict values for the parameter "name" to only contain capital alphabetic characters.
 Regular expressions must use the standard Go syntax as described in the regexp package. (https://code.google.com/p/re2/wiki/Syntax)
-This feature requires the use of a CurlyRouter.
+This feature is supported by the CurlyRouter (default), the RouterJSR311 and the RadixRouter.
+
+Instead of a regular expression, the name of a path parameter constraint can be used, e.g. /persons/{id:int} or /orders/{ref:uuid}.
+Available constraints are int, uint, uuid, date and alpha ; use RegisterPathParameterConstraint to add your own.
+Constraints are checked by all Routers ; a request path that violates a constraint does not select the Route.
+The DataType and DataFormat of a documented path Parameter are set from its constraint.
 
 Containers
 
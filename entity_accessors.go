@@ -44,6 +44,7 @@ type entityReaderWriters struct {
 func init() {
 	RegisterEntityAccessor(MIME_JSON, NewEntityAccessorJSON(MIME_JSON))
 	RegisterEntityAccessor(MIME_XML, NewEntityAccessorXML(MIME_XML))
+	RegisterEntityAccessor(MIME_MULTIPART_FORM, entityMultipartAccess{})
 }
 
 // RegisterEntityAccessor add/overrides the ReaderWriter for encoding content with this MIME type.
@@ -66,10 +67,27 @@ func NewEntityAccessorXML(contentType string) EntityReaderWriter {
 }
 
 // accessorAt returns the registered ReaderWriter for this MIME type.
+// Parameters are ignored if needed and a structured syntax suffix (RFC 6838) falls back to the accessor of that format,
+// e.g. application/vnd.acme.order+json uses the accessor of application/json.
 func (r *entityReaderWriters) accessorAt(mime string) (EntityReaderWriter, bool) {
 	r.protection.RLock()
 	defer r.protection.RUnlock()
 	er, ok := r.accessors[mime]
+	if ok {
+		return er, ok
+	}
+	parsed := parseMediaType(mime)
+	if er, ok = r.accessors[parsed.String()]; ok {
+		return er, ok
+	}
+	if len(parsed.suffix) > 0 {
+		if er, ok = r.accessors[parsed.mainType+"/"+parsed.suffix]; ok {
+			return er, ok
+		}
+		if er, ok = r.accessors["application/"+parsed.suffix]; ok {
+			return er, ok
+		}
+	}
 	if !ok {
 		// retry with reverse lookup
 		// more expensive but we are in an exceptional situation anyway
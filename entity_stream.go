@@ -0,0 +1,120 @@
+package restful
+
+// Copyright 2024 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+)
+
+// EntityIterator returns the next entity of a stream or io.EOF if there are no more entities.
+// An iterator that blocks, e.g. waiting for a database row, should observe the context given to WriteEntityStream.
+type EntityIterator func() (interface{}, error)
+
+// recordSeparator starts each JSON text of an application/json-seq stream (RFC 7464)
+const recordSeparator = 0x1E
+
+// WriteEntityStream writes the entities of the source one by one, without collecting them in memory, and flushes after each entity.
+// The source is a receive channel of any element type (closed when done) or an EntityIterator (func() (interface{}, error)).
+// The contentType is one of MIME_NDJSON (one entity per line), MIME_JSON_SEQ (RFC 7464) or MIME_JSON (a JSON array).
+// Writing stops when the context is done, e.g. the request context when the client disconnects, and then its error is returned.
+// The context is checked between entities and while waiting on a channel ; an EntityIterator that blocks cannot be
+// interrupted by cancelling the context, it must observe the context itself.
+// Because the status and headers are written before the first entity, an error of the source only ends the stream.
+// For MIME_JSON this leaves the array unterminated (no closing ']') such that the client fails to decode the body,
+// which is how it can detect the incomplete result.
+// Example:
+//
+//	resp.WriteEntityStream(req.Request.Context(), restful.MIME_NDJSON, rows)
+func (r *Response) WriteEntityStream(ctx context.Context, contentType string, source interface{}) error {
+	next, err := entityStreamSource(ctx, source)
+	if err != nil {
+		return err
+	}
+	format := parseMediaType(contentType).String()
+	if format != MIME_NDJSON && format != MIME_JSON_SEQ && format != MIME_JSON {
+		return fmt.Errorf("unable to stream entities as %q, use %s, %s or %s", contentType, MIME_NDJSON, MIME_JSON_SEQ, MIME_JSON)
+	}
+	array, sequence := format == MIME_JSON, format == MIME_JSON_SEQ
+	r.Header().Set(HEADER_ContentType, contentType)
+	r.WriteHeader(http.StatusOK)
+	buffer := new(bytes.Buffer)
+	for count := 0; ; count++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		entity, err := next()
+		if err == io.EOF {
+			if !array {
+				return nil
+			}
+			buffer.Reset()
+			if count == 0 {
+				// an empty stream is still a valid array
+				buffer.WriteString("[")
+			}
+			buffer.WriteString("]\n")
+			_, err = r.Write(buffer.Bytes())
+			return err
+		}
+		if err != nil {
+			if array && count > 0 && trace {
+				traceLogger.Printf("entity stream ended after %d entities without terminating the JSON array: %v\n", count, err)
+			}
+			return err
+		}
+		buffer.Reset()
+		if sequence {
+			buffer.WriteByte(recordSeparator)
+		}
+		if array {
+			// the newline of the encoder is kept to separate the elements
+			if count == 0 {
+				buffer.WriteString("[")
+			} else {
+				buffer.WriteString(",")
+			}
+		}
+		if err := NewEncoder(buffer).Encode(entity); err != nil {
+			return err
+		}
+		if _, err := r.Write(buffer.Bytes()); err != nil {
+			return err
+		}
+		r.Flush()
+	}
+}
+
+// entityStreamSource returns an iterator for the channel or EntityIterator that returns the error of the context when it is done.
+func entityStreamSource(ctx context.Context, source interface{}) (EntityIterator, error) {
+	switch iterator := source.(type) {
+	case EntityIterator:
+		return iterator, nil
+	case func() (interface{}, error):
+		return iterator, nil
+	}
+	channel := reflect.ValueOf(source)
+	if channel.Kind() != reflect.Chan || channel.Type().ChanDir()&reflect.RecvDir == 0 {
+		return nil, fmt.Errorf("unable to stream entities from %T, use a receive channel or an EntityIterator", source)
+	}
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+		{Dir: reflect.SelectRecv, Chan: channel},
+	}
+	return func() (interface{}, error) {
+		chosen, value, ok := reflect.Select(cases)
+		if chosen == 0 {
+			return nil, ctx.Err()
+		}
+		if !ok {
+			return nil, io.EOF
+		}
+		return value.Interface(), nil
+	}, nil
+}
@@ -0,0 +1,154 @@
+package restful
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type streamRow struct {
+	ID int `json:"id"`
+}
+
+func streamRows(n int) EntityIterator {
+	i := 0
+	return func() (interface{}, error) {
+		if i == n {
+			return nil, io.EOF
+		}
+		i++
+		return streamRow{ID: i}, nil
+	}
+}
+
+// go test -v -test.run TestWriteEntityStream ...restful
+func TestWriteEntityStream(t *testing.T) {
+	channel := make(chan streamRow, 2)
+	channel <- streamRow{ID: 1}
+	channel <- streamRow{ID: 2}
+	close(channel)
+	tests := []struct {
+		name        string
+		contentType string
+		source      interface{}
+		body        string
+	}{
+		{"ndjson channel", MIME_NDJSON, (<-chan streamRow)(channel), "{\"id\":1}\n{\"id\":2}\n"},
+		{"ndjson iterator", MIME_NDJSON, streamRows(2), "{\"id\":1}\n{\"id\":2}\n"},
+		{"json-seq", MIME_JSON_SEQ, streamRows(2), "\x1e{\"id\":1}\n\x1e{\"id\":2}\n"},
+		{"array", MIME_JSON, streamRows(3), "[{\"id\":1}\n,{\"id\":2}\n,{\"id\":3}\n]\n"},
+		{"empty array", MIME_JSON, streamRows(0), "[]\n"},
+		{"plain func", MIME_NDJSON, func() (interface{}, error) { return nil, io.EOF }, ""},
+	}
+	for _, each := range tests {
+		t.Run(each.name, func(t *testing.T) {
+			httpWriter := httptest.NewRecorder()
+			resp := NewResponse(httpWriter)
+			if err := resp.WriteEntityStream(context.Background(), each.contentType, each.source); err != nil {
+				t.Fatal(err)
+			}
+			if got, want := httpWriter.Body.String(), each.body; got != want {
+				t.Errorf("got %q want %q", got, want)
+			}
+			if got, want := httpWriter.Header().Get(HEADER_ContentType), each.contentType; got != want {
+				t.Errorf("got content type %q want %q", got, want)
+			}
+			if got, want := httpWriter.Flushed, strings.Contains(each.body, "id"); got != want {
+				t.Errorf("got flushed %v want %v", got, want)
+			}
+		})
+	}
+}
+
+// go test -v -test.run TestWriteEntityStreamInvalid ...restful
+func TestWriteEntityStreamInvalid(t *testing.T) {
+	resp := NewResponse(httptest.NewRecorder())
+	if err := resp.WriteEntityStream(context.Background(), MIME_XML, streamRows(1)); err == nil {
+		t.Error("expected error for unsupported content type")
+	}
+	if err := resp.WriteEntityStream(context.Background(), MIME_NDJSON, []streamRow{}); err == nil {
+		t.Error("expected error for unsupported source")
+	}
+	if err := resp.WriteEntityStream(context.Background(), MIME_NDJSON, make(chan<- streamRow)); err == nil {
+		t.Error("expected error for send-only channel")
+	}
+}
+
+// go test -v -test.run TestWriteEntityStreamCancelled ...restful
+func TestWriteEntityStreamCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	rows := streamRows(10)
+	source := EntityIterator(func() (interface{}, error) {
+		row, err := rows()
+		if row.(streamRow).ID == 2 {
+			cancel()
+		}
+		return row, err
+	})
+	httpWriter := httptest.NewRecorder()
+	if err := NewResponse(httpWriter).WriteEntityStream(ctx, MIME_NDJSON, source); err != context.Canceled {
+		t.Errorf("got %v want %v", err, context.Canceled)
+	}
+	if got, want := httpWriter.Body.String(), "{\"id\":1}\n{\"id\":2}\n"; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+	// a blocked channel
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if err := NewResponse(httptest.NewRecorder()).WriteEntityStream(ctx, MIME_NDJSON, make(chan streamRow)); err != context.Canceled {
+		t.Errorf("got %v want %v", err, context.Canceled)
+	}
+}
+
+// go test -v -test.run TestWriteEntityStreamCompressed ...restful
+func TestWriteEntityStreamCompressed(t *testing.T) {
+	wc := NewContainer()
+	wc.EnableContentEncoding(true)
+	ws := new(WebService).Path("/")
+	ws.Route(ws.GET("/rows").Produces(MIME_NDJSON).To(func(req *Request, resp *Response) {
+		resp.WriteEntityStream(req.Request.Context(), MIME_NDJSON, streamRows(2))
+	}).Operation("rows"))
+	wc.Add(ws)
+	httpRequest, _ := http.NewRequest("GET", "/rows", nil)
+	httpRequest.Header.Set(HEADER_AcceptEncoding, ENCODING_GZIP)
+	httpWriter := httptest.NewRecorder()
+	wc.ServeHTTP(httpWriter, httpRequest)
+	if got, want := httpWriter.Header().Get(HEADER_ContentEncoding), ENCODING_GZIP; got != want {
+		t.Fatalf("got encoding %q want %q", got, want)
+	}
+	reader, err := gzip.NewReader(httpWriter.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(reader)
+	if got, want := string(body), "{\"id\":1}\n{\"id\":2}\n"; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+}
+
+// go test -v -test.run TestCompressingResponseWriterFlush ...restful
+func TestCompressingResponseWriterFlush(t *testing.T) {
+	httpWriter := httptest.NewRecorder()
+	compressing, _ := NewCompressingResponseWriter(httpWriter, ENCODING_GZIP)
+	compressing.Write([]byte("first\n"))
+	compressing.Flush()
+	// the compressor is not closed but the written data can be decompressed
+	reader, err := gzip.NewReader(httpWriter.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line, err := bufio.NewReader(reader).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := line, "first\n"; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+	compressing.Close()
+}
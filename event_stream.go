@@ -0,0 +1,191 @@
+package restful
+
+// Copyright 2024 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event is a message of a Server-Sent Events stream (text/event-stream).
+type Event struct {
+	// ID is sent back by the client in the Last-Event-ID header when it reconnects. Optional.
+	ID string
+	// Event is the name of the event type, e.g. update. If empty then the client dispatches a message event.
+	Event string
+	// Data is the payload ; a string or []byte is written as is, any other value is written as JSON.
+	// Each line of the payload is written as a separate data field. Optional.
+	Data interface{}
+	// Retry tells the client how long to wait before reconnecting. Optional.
+	Retry time.Duration
+}
+
+// EventStream writes Server-Sent Events to a Response. It is safe for concurrent use.
+// Each event is flushed, also through a CompressingResponseWriter, such that clients receive it immediately.
+type EventStream struct {
+	response  *Response
+	ctx       context.Context
+	mutex     sync.Mutex
+	closed    chan struct{}
+	closeOnce sync.Once
+	keepAlive sync.WaitGroup
+}
+
+// EventStream writes the status and headers of a text/event-stream response and returns the stream to send events.
+// The stream ends when the context is done, e.g. the request context when the client disconnects.
+// Close the stream before the RouteFunction returns ; if not, the Container closes it when the RouteFunction
+// and the filters have returned such that the keep-alive does not write to a finished response.
+// Example:
+//
+//	stream := resp.EventStream(req.Request.Context())
+//	defer stream.Close()
+//	stream.KeepAlive(15 * time.Second)
+//	stream.Send(restful.Event{ID: "42", Event: "update", Data: dashboard})
+func (r *Response) EventStream(ctx context.Context) *EventStream {
+	r.Header().Set(HEADER_ContentType, MIME_EVENT_STREAM)
+	r.Header().Set(HEADER_CacheControl, "no-cache")
+	r.WriteHeader(http.StatusOK)
+	r.Flush()
+	stream := &EventStream{response: r, ctx: ctx, closed: make(chan struct{})}
+	r.eventStreams = append(r.eventStreams, stream)
+	return stream
+}
+
+// closeEventStreams closes the event streams of the response, if any.
+func (r *Response) closeEventStreams() {
+	for _, each := range r.eventStreams {
+		each.Close()
+	}
+}
+
+// LastEventID returns the value of the Last-Event-ID header that is sent by a client that reconnects to an event stream.
+func (r *Request) LastEventID() string {
+	return r.Request.Header.Get(HEADER_LastEventID)
+}
+
+// EventStream documents that the Route responds with Server-Sent Events (text/event-stream)
+// and that clients can send the Last-Event-ID header. The sample is the type of the event data, use nil if not applicable.
+func (b *RouteBuilder) EventStream(sample interface{}) *RouteBuilder {
+	b.Produces(MIME_EVENT_STREAM)
+	if sample != nil {
+		b.Writes(sample)
+	}
+	return b.Param(HeaderParameter(HEADER_LastEventID, "the id of the last event received before reconnecting").DataType("string"))
+}
+
+// Send writes and flushes the event.
+// Returns an error if the stream is closed, the context is done or the ID or Event contains a newline.
+func (s *EventStream) Send(event Event) error {
+	if strings.ContainsAny(event.ID, "\r\n\x00") || strings.ContainsAny(event.Event, "\r\n") {
+		return errors.New("event id and name cannot contain a newline")
+	}
+	buffer := new(bytes.Buffer)
+	if event.Retry > 0 {
+		fmt.Fprintf(buffer, "retry: %d\n", event.Retry/time.Millisecond)
+	}
+	if len(event.ID) > 0 {
+		fmt.Fprintf(buffer, "id: %s\n", event.ID)
+	}
+	if len(event.Event) > 0 {
+		fmt.Fprintf(buffer, "event: %s\n", event.Event)
+	}
+	if event.Data != nil {
+		data, err := eventData(event.Data)
+		if err != nil {
+			return err
+		}
+		for _, line := range strings.Split(data, "\n") {
+			fmt.Fprintf(buffer, "data: %s\n", line)
+		}
+	}
+	buffer.WriteString("\n")
+	return s.write(buffer.Bytes())
+}
+
+// Comment writes and flushes a comment, which is ignored by clients. Each line of the text is a separate comment.
+func (s *EventStream) Comment(text string) error {
+	buffer := new(bytes.Buffer)
+	for _, line := range strings.Split(normalizedNewlines(text), "\n") {
+		fmt.Fprintf(buffer, ": %s\n", line)
+	}
+	buffer.WriteString("\n")
+	return s.write(buffer.Bytes())
+}
+
+// KeepAlive sends a comment every interval to prevent proxies and clients from closing an idle connection.
+// It stops when the stream is closed or the context is done.
+func (s *EventStream) KeepAlive(interval time.Duration) {
+	s.keepAlive.Add(1)
+	go func() {
+		defer s.keepAlive.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.closed:
+				return
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.Comment("keep-alive"); err != nil {
+					return
+				}
+			}
+		}
+	}()
+}
+
+// Close stops the keep-alive and waits for it to finish. Sending on a closed stream returns an error.
+func (s *EventStream) Close() {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+	})
+	s.keepAlive.Wait()
+}
+
+// write writes and flushes the data unless the stream is closed or the context is done.
+func (s *EventStream) write(data []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	select {
+	case <-s.closed:
+		return errors.New("event stream is closed")
+	default:
+	}
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	if _, err := s.response.Write(data); err != nil {
+		return err
+	}
+	s.response.Flush()
+	return nil
+}
+
+// eventData returns the data as text with newlines only.
+func eventData(data interface{}) (string, error) {
+	switch value := data.(type) {
+	case string:
+		return normalizedNewlines(value), nil
+	case []byte:
+		return normalizedNewlines(string(value)), nil
+	}
+	buffer := new(bytes.Buffer)
+	if err := NewEncoder(buffer).Encode(data); err != nil {
+		return "", err
+	}
+	return normalizedNewlines(strings.TrimSuffix(buffer.String(), "\n")), nil
+}
+
+// normalizedNewlines replaces the CRLF and CR line endings by LF.
+func normalizedNewlines(text string) string {
+	return strings.Replace(strings.Replace(text, "\r\n", "\n", -1), "\r", "\n", -1)
+}
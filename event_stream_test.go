@@ -0,0 +1,165 @@
+package restful
+
+import (
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// go test -v -test.run TestEventStreamSend ...restful
+func TestEventStreamSend(t *testing.T) {
+	tests := []struct {
+		name  string
+		event Event
+		text  string
+	}{
+		{"data", Event{Data: "hello"}, "data: hello\n\n"},
+		{"all fields", Event{ID: "42", Event: "update", Data: "a", Retry: 3 * time.Second}, "retry: 3000\nid: 42\nevent: update\ndata: a\n\n"},
+		{"multiline", Event{Data: "a\r\nb\nc"}, "data: a\ndata: b\ndata: c\n\n"},
+		{"bytes", Event{Data: []byte("raw")}, "data: raw\n\n"},
+		{"json", Event{Event: "row", Data: streamRow{ID: 7}}, "event: row\ndata: {\"id\":7}\n\n"},
+		{"id only", Event{ID: "1"}, "id: 1\n\n"},
+	}
+	for _, each := range tests {
+		t.Run(each.name, func(t *testing.T) {
+			httpWriter := httptest.NewRecorder()
+			stream := NewResponse(httpWriter).EventStream(context.Background())
+			if err := stream.Send(each.event); err != nil {
+				t.Fatal(err)
+			}
+			stream.Close()
+			if got, want := httpWriter.Body.String(), each.text; got != want {
+				t.Errorf("got %q want %q", got, want)
+			}
+			if got, want := httpWriter.Header().Get(HEADER_ContentType), MIME_EVENT_STREAM; got != want {
+				t.Errorf("got content type %q want %q", got, want)
+			}
+			if got, want := httpWriter.Header().Get(HEADER_CacheControl), "no-cache"; got != want {
+				t.Errorf("got cache control %q want %q", got, want)
+			}
+			if !httpWriter.Flushed {
+				t.Error("expected flushed response")
+			}
+		})
+	}
+}
+
+// go test -v -test.run TestEventStreamErrors ...restful
+func TestEventStreamErrors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	httpWriter := httptest.NewRecorder()
+	stream := NewResponse(httpWriter).EventStream(ctx)
+	if err := stream.Send(Event{ID: "1\n2"}); err == nil {
+		t.Error("expected error for newline in id")
+	}
+	if err := stream.Comment("first\nsecond"); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if err := stream.Send(Event{Data: "late"}); err != context.Canceled {
+		t.Errorf("got %v want %v", err, context.Canceled)
+	}
+	stream.Close()
+	stream.Close()
+	if got, want := httpWriter.Body.String(), ": first\n: second\n\n"; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+	stream = NewResponse(httptest.NewRecorder()).EventStream(context.Background())
+	stream.Close()
+	if err := stream.Send(Event{Data: "closed"}); err == nil {
+		t.Error("expected error for closed stream")
+	}
+}
+
+// go test -v -test.run TestEventStreamKeepAlive ...restful
+func TestEventStreamKeepAlive(t *testing.T) {
+	httpWriter := httptest.NewRecorder()
+	stream := NewResponse(httpWriter).EventStream(context.Background())
+	stream.KeepAlive(time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	stream.Send(Event{Data: "done"})
+	stream.Close()
+	// no writes after Close
+	body := httpWriter.Body.String()
+	time.Sleep(5 * time.Millisecond)
+	if got, want := httpWriter.Body.String(), body; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+	if !strings.HasPrefix(body, ": keep-alive\n\n") {
+		t.Errorf("got %q want keep-alive comments", body)
+	}
+	if !strings.HasSuffix(body, "data: done\n\n") {
+		t.Errorf("got %q want event at the end", body)
+	}
+}
+
+// go test -v -test.run TestEventStreamClosedAfterRoute ...restful
+func TestEventStreamClosedAfterRoute(t *testing.T) {
+	wc := NewContainer()
+	ws := new(WebService).Path("/")
+	ws.Route(ws.GET("/events").EventStream(nil).To(func(req *Request, resp *Response) {
+		stream := resp.EventStream(req.Request.Context())
+		stream.KeepAlive(time.Millisecond)
+		time.Sleep(5 * time.Millisecond)
+		// returns without Close
+	}).Operation("events"))
+	wc.Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "/events", nil)
+	httpRequest.Header.Set(HEADER_Accept, MIME_EVENT_STREAM)
+	httpWriter := httptest.NewRecorder()
+	wc.ServeHTTP(httpWriter, httpRequest)
+	// no writes after ServeHTTP returns
+	body := httpWriter.Body.String()
+	time.Sleep(5 * time.Millisecond)
+	if got, want := httpWriter.Body.String(), body; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+}
+
+// go test -v -test.run TestEventStreamRoute ...restful
+func TestEventStreamRoute(t *testing.T) {
+	wc := NewContainer()
+	wc.EnableContentEncoding(true)
+	ws := new(WebService).Path("/")
+	ws.Route(ws.GET("/events").EventStream(streamRow{}).To(func(req *Request, resp *Response) {
+		stream := resp.EventStream(req.Request.Context())
+		defer stream.Close()
+		stream.Send(Event{ID: "2", Data: "after " + req.LastEventID()})
+	}).Operation("events"))
+	wc.Add(ws)
+
+	route := ws.Routes()[0]
+	if got, want := route.Produces, []string{MIME_EVENT_STREAM}; len(got) != 1 || got[0] != want[0] {
+		t.Errorf("got produces %v want %v", got, want)
+	}
+	if got, want := route.WriteSample, interface{}(streamRow{}); got != want {
+		t.Errorf("got sample %v want %v", got, want)
+	}
+	if got, want := route.ParameterDocs[0].Data().Name, HEADER_LastEventID; got != want {
+		t.Errorf("got parameter %q want %q", got, want)
+	}
+
+	httpRequest, _ := http.NewRequest("GET", "/events", nil)
+	httpRequest.Header.Set(HEADER_Accept, MIME_EVENT_STREAM)
+	httpRequest.Header.Set(HEADER_AcceptEncoding, ENCODING_GZIP)
+	httpRequest.Header.Set(HEADER_LastEventID, "1")
+	httpWriter := httptest.NewRecorder()
+	wc.ServeHTTP(httpWriter, httpRequest)
+	if got, want := httpWriter.Code, http.StatusOK; got != want {
+		t.Fatalf("got status %d want %d", got, want)
+	}
+	reader, err := gzip.NewReader(httpWriter.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(reader)
+	if got, want := string(body), "id: 2\ndata: after 1\n\n"; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+}
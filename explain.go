@@ -0,0 +1,264 @@
+package restful
+
+// Copyright 2024 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+// RouteRejection tells which check rejected a WebService or Route when selecting the Route for a request.
+type RouteRejection string
+
+const (
+	// RejectedByHost means the host of the request does not match any host pattern of the WebService
+	RejectedByHost RouteRejection = "host"
+	// RejectedByApiVersion means another ApiVersion of the WebService was requested
+	RejectedByApiVersion RouteRejection = "api version"
+	// RejectedByPath means the request path does not match the path of the WebService or Route
+	RejectedByPath RouteRejection = "path"
+	// RejectedByScore means the WebService or Route matches the request but another one was preferred
+	RejectedByScore RouteRejection = "score"
+	// RejectedByCondition means one of the If conditions of the Route returned false
+	RejectedByCondition RouteRejection = "condition"
+	// RejectedByMethod means the request method is not the method of the Route
+	RejectedByMethod RouteRejection = "method"
+	// RejectedByConsumes means the Content-Type of the request is not one of the Consumes of the Route
+	RejectedByConsumes RouteRejection = "consumes"
+	// RejectedByAccept means the Accept of the request does not match any of the Produces of the Route
+	RejectedByAccept RouteRejection = "accept"
+)
+
+// SelectionScore holds the values a router uses to rank matching WebServices and Routes.
+// CurlyRouter (and RadixRouter) use Score, ParamCount and StaticCount. RouterJSR311 uses MatchesCount, LiteralCount and VarCount.
+type SelectionScore struct {
+	Score        int // score of a WebService root path
+	ParamCount   int // number of path parameters
+	StaticCount  int // number of static path elements
+	MatchesCount int // number of capturing groups in the path expression
+	LiteralCount int // number of literal characters in the path expression
+	VarCount     int // number of variables in the path expression
+}
+
+// RouteExplanation describes how a Route was considered for a request.
+type RouteExplanation struct {
+	Method    string
+	Path      string
+	Operation string
+	Score     SelectionScore
+	Selected  bool
+	Rejection RouteRejection // empty if selected
+}
+
+// WebServiceExplanation describes how a WebService and its Routes were considered for a request.
+type WebServiceExplanation struct {
+	RootPath   string
+	ApiVersion string
+	Score      SelectionScore
+	Selected   bool
+	Rejection  RouteRejection     // empty if selected
+	Routes     []RouteExplanation // empty if the root path does not match
+}
+
+// SelectionExplanation is the result of Container.Explain.
+type SelectionExplanation struct {
+	Method      string
+	Path        string
+	WebServices []WebServiceExplanation
+	Selected    *Route // nil if no Route was selected
+	Error       error  // the error that would be handled by the ServiceErrorHandler, if any
+}
+
+// Explain reports how the Container selects a WebService and Route for the request without calling it.
+// It lists every WebService and every Route of a WebService with a matching root path, its score and the check that rejected it.
+// Use it in tests or when debugging unexpected 404, 405, 406 or 415 responses:
+//
+//	t.Log(container.Explain(httpRequest))
+//
+// Note that the If conditions of Routes are called.
+func (c *Container) Explain(httpRequest *http.Request) SelectionExplanation {
+	c.webServicesLock.RLock()
+	defer c.webServicesLock.RUnlock()
+
+	explanation := SelectionExplanation{Method: httpRequest.Method, Path: httpRequest.URL.Path}
+	candidates := c.webServices
+	var err error
+	if c.apiVersionNegotiation != nil {
+		candidates, err = c.apiVersionNegotiation.selectWebServices(c.apiVersionGroups, httpRequest)
+		httpRequest = c.apiVersionNegotiation.routingRequest(httpRequest)
+	}
+	var selectedService *WebService
+	var selectedRoute *Route
+	routePath := httpRequest.URL.Path
+	if c.pathPolicy != nil {
+		routePath = c.pathPolicy.requestPath(httpRequest)
+	}
+	if err == nil {
+		var selectedPath string
+		selectedService, selectedRoute, selectedPath, err = c.selectRoute(candidates, httpRequest)
+		if err != nil {
+			if ws, rt, rp, ok := c.selectGETRouteForHEAD(candidates, httpRequest, err); ok {
+				selectedService, selectedRoute, selectedPath, err = ws, rt, rp, nil
+			}
+		}
+		if selectedService != nil {
+			routePath = selectedPath
+		}
+	}
+	explanation.Selected, explanation.Error = selectedRoute, err
+	if c.matrixParametersEnabled {
+		routePath = withoutMatrixParameters(routePath)
+	}
+
+	_, isJSR311 := c.router.(RouterJSR311)
+	matchingHost := selectWebServicesByHost(c.webServices, httpRequest.Host)
+	for _, each := range c.webServices {
+		ws := WebServiceExplanation{RootPath: each.rootPath, ApiVersion: each.apiVersion, Selected: each == selectedService}
+		switch {
+		case !containsWebService(matchingHost, each):
+			ws.Rejection = RejectedByHost
+		case !containsWebService(candidates, each):
+			ws.Rejection = RejectedByApiVersion
+		default:
+			var remainder string
+			var matches bool
+			if isJSR311 {
+				matches, remainder, ws.Score = explainJSR311WebService(each, routePath)
+			} else {
+				matches, ws.Score.Score = CurlyRouter{}.computeWebserviceScore(tokenizePath(routePath), each.pathExpr.tokens)
+			}
+			if !matches {
+				ws.Rejection = RejectedByPath
+				break
+			}
+			if !ws.Selected {
+				ws.Rejection = RejectedByScore
+			}
+			for _, route := range each.Routes() {
+				ws.Routes = append(ws.Routes, explainRoute(route, routePath, remainder, isJSR311, httpRequest, selectedRoute))
+			}
+		}
+		explanation.WebServices = append(explanation.WebServices, ws)
+	}
+	return explanation
+}
+
+// explainJSR311WebService returns whether the root path matches, the remainder of the path and the score.
+func explainJSR311WebService(ws *WebService, requestPath string) (bool, string, SelectionScore) {
+	matches := ws.pathExpr.Matcher.FindStringSubmatch(requestPath)
+	if matches == nil {
+		return false, "", SelectionScore{}
+	}
+	return true, matches[len(matches)-1], SelectionScore{
+		MatchesCount: len(matches),
+		LiteralCount: ws.pathExpr.LiteralCount,
+		VarCount:     ws.pathExpr.VarCount,
+	}
+}
+
+// explainRoute performs the checks of the router in the same order.
+func explainRoute(route Route, requestPath, remainder string, isJSR311 bool, httpRequest *http.Request, selected *Route) RouteExplanation {
+	explained := RouteExplanation{Method: route.Method, Path: route.Path, Operation: route.Operation}
+	// path
+	if isJSR311 {
+		matches := route.pathExpr.Matcher.FindStringSubmatch(remainder)
+		if matches == nil || (matches[len(matches)-1] != "" && matches[len(matches)-1] != "/") {
+			explained.Rejection = RejectedByPath
+			return explained
+		}
+		explained.Score.MatchesCount = len(matches) - 1
+		explained.Score.LiteralCount = route.pathExpr.LiteralCount
+		explained.Score.VarCount = route.pathExpr.VarCount
+	} else {
+		matches, paramCount, staticCount := CurlyRouter{}.matchesRouteByPathTokens(route.pathParts, tokenizePath(requestPath), route.hasCustomVerb)
+		if !matches {
+			explained.Rejection = RejectedByPath
+			return explained
+		}
+		explained.Score.ParamCount, explained.Score.StaticCount = paramCount, staticCount
+	}
+	for _, fn := range route.If {
+		if !fn(httpRequest) {
+			explained.Rejection = RejectedByCondition
+			return explained
+		}
+	}
+	if selected != nil && sameRoute(route, *selected) {
+		explained.Selected = true
+		return explained
+	}
+	if route.Method != httpRequest.Method {
+		explained.Rejection = RejectedByMethod
+		return explained
+	}
+	if !route.matchesContentType(httpRequest.Header.Get(HEADER_ContentType)) {
+		explained.Rejection = RejectedByConsumes
+		return explained
+	}
+	accept := httpRequest.Header.Get(HEADER_Accept)
+	if len(accept) == 0 {
+		accept = "*/*"
+	}
+	if !route.matchesAccept(accept) {
+		explained.Rejection = RejectedByAccept
+		return explained
+	}
+	explained.Rejection = RejectedByScore
+	return explained
+}
+
+// sameRoute returns whether the two Routes are copies of the same Route.
+func sameRoute(route, other Route) bool {
+	return route.Method == other.Method &&
+		route.Path == other.Path &&
+		route.Operation == other.Operation &&
+		reflect.DeepEqual(route.Produces, other.Produces) &&
+		reflect.DeepEqual(route.Consumes, other.Consumes)
+}
+
+func containsWebService(webServices []*WebService, ws *WebService) bool {
+	for _, each := range webServices {
+		if each == ws {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns a multi-line text representation of the explanation.
+func (e SelectionExplanation) String() string {
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "%s %s", e.Method, e.Path)
+	if e.Selected != nil {
+		fmt.Fprintf(&buffer, " selects %s %s (%s)", e.Selected.Method, e.Selected.Path, e.Selected.Operation)
+	}
+	if e.Error != nil {
+		fmt.Fprintf(&buffer, " fails with %v", e.Error)
+	}
+	buffer.WriteString("\n")
+	for _, ws := range e.WebServices {
+		fmt.Fprintf(&buffer, "  WebService %s", ws.RootPath)
+		if len(ws.ApiVersion) > 0 {
+			fmt.Fprintf(&buffer, " (version %s)", ws.ApiVersion)
+		}
+		fmt.Fprintf(&buffer, " %s\n", explainStatus(ws.Selected, ws.Rejection))
+		for _, route := range ws.Routes {
+			fmt.Fprintf(&buffer, "    %s %s (%s) %+v %s\n", route.Method, route.Path, route.Operation, route.Score, explainStatus(route.Selected, route.Rejection))
+		}
+	}
+	return buffer.String()
+}
+
+func explainStatus(selected bool, rejection RouteRejection) string {
+	if selected {
+		return "selected"
+	}
+	if len(rejection) == 0 {
+		return "not selected"
+	}
+	return "rejected by " + string(rejection)
+}
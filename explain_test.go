@@ -0,0 +1,201 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newExplainContainer(router RouteSelector) *Container {
+	wc := NewContainer()
+	wc.Router(router)
+	users := new(WebService).Path("/users").Produces(MIME_JSON)
+	users.Route(users.GET("/{id}").To(dummy).Operation("findUser"))
+	users.Route(users.GET("/me").To(dummy).Operation("findMe"))
+	users.Route(users.GET("/{id}").If(func(*http.Request) bool { return false }).To(dummy).Operation("findUserIf"))
+	users.Route(users.PUT("/{id}").Consumes(MIME_XML).To(dummy).Operation("updateUser"))
+	users.Route(users.GET("/{id}/orders").To(dummy).Operation("listOrders"))
+	wc.Add(users)
+	orders := new(WebService).Path("/orders")
+	orders.Route(orders.GET("/{id}").To(dummy).Operation("findOrder"))
+	wc.Add(orders)
+	return wc
+}
+
+// routeRejections returns the rejection of each Route of the WebService by its operation.
+func routeRejections(ws WebServiceExplanation) map[string]RouteRejection {
+	rejections := map[string]RouteRejection{}
+	for _, each := range ws.Routes {
+		rejections[each.Operation] = each.Rejection
+	}
+	return rejections
+}
+
+// go test -v -test.run TestContainer_Explain ...restful
+func TestContainer_Explain(t *testing.T) {
+	for name, router := range map[string]RouteSelector{"curly": CurlyRouter{}, "jsr311": RouterJSR311{}} {
+		wc := newExplainContainer(router)
+		explanation := wc.Explain(httptest.NewRequest("GET", "/users/42", nil))
+		if explanation.Error != nil {
+			t.Fatalf("[%s] unexpected error %v", name, explanation.Error)
+		}
+		if explanation.Selected == nil || "findUser" != explanation.Selected.Operation {
+			t.Fatalf("[%s] findUser expected, got %v", name, explanation.Selected)
+		}
+		if len(explanation.WebServices) != 2 || !explanation.WebServices[0].Selected {
+			t.Fatalf("[%s] the users WebService must be selected, got %v", name, explanation)
+		}
+		if RejectedByPath != explanation.WebServices[1].Rejection {
+			t.Errorf("[%s] the orders WebService must be rejected by path, got %v", name, explanation.WebServices[1].Rejection)
+		}
+		for operation, want := range map[string]RouteRejection{
+			"findUser":   "",
+			"findMe":     RejectedByPath,
+			"findUserIf": RejectedByCondition,
+			"updateUser": RejectedByMethod,
+			"listOrders": RejectedByPath,
+		} {
+			if got := routeRejections(explanation.WebServices[0])[operation]; got != want {
+				t.Errorf("[%s] %s: got %v want %v", name, operation, got, want)
+			}
+		}
+	}
+}
+
+// go test -v -test.run TestContainer_ExplainDoesNotCallRoute ...restful
+func TestContainer_ExplainDoesNotCallRoute(t *testing.T) {
+	called := false
+	ws := new(WebService).Path("/users")
+	ws.Route(ws.GET("/{id}").To(func(req *Request, resp *Response) { called = true }).Operation("findUser"))
+	wc := NewContainer()
+	wc.Add(ws)
+	wc.Explain(httptest.NewRequest("GET", "/users/42", nil))
+	if called {
+		t.Error("the Route function must not be called")
+	}
+}
+
+// go test -v -test.run TestContainer_ExplainScore ...restful
+func TestContainer_ExplainScore(t *testing.T) {
+	explanation := newExplainContainer(CurlyRouter{}).Explain(httptest.NewRequest("GET", "/users/me", nil))
+	if explanation.Selected == nil || "findMe" != explanation.Selected.Operation {
+		t.Fatal("the static Route must be preferred, got", explanation.Selected)
+	}
+	if got := routeRejections(explanation.WebServices[0])["findUser"]; RejectedByScore != got {
+		t.Error("the matching parameter Route must be rejected by score, got", got)
+	}
+	for _, each := range explanation.WebServices[0].Routes {
+		if each.Operation == "findMe" && each.Score.StaticCount != 2 {
+			t.Errorf("two static path elements expected, got %+v", each.Score)
+		}
+	}
+}
+
+// go test -v -test.run TestContainer_ExplainUnknownPath ...restful
+func TestContainer_ExplainUnknownPath(t *testing.T) {
+	explanation := newExplainContainer(CurlyRouter{}).Explain(httptest.NewRequest("GET", "/customers/42", nil))
+	if ser, ok := explanation.Error.(ServiceError); !ok || 404 != ser.Code {
+		t.Fatal("404 expected, got", explanation.Error)
+	}
+	if explanation.Selected != nil {
+		t.Error("no Route expected, got", explanation.Selected)
+	}
+	for _, each := range explanation.WebServices {
+		if RejectedByPath != each.Rejection || len(each.Routes) != 0 {
+			t.Errorf("%s must be rejected by path without Routes, got %v with %d Routes", each.RootPath, each.Rejection, len(each.Routes))
+		}
+	}
+	if !strings.Contains(explanation.String(), "GET /customers/42 fails with [ServiceError:404]") {
+		t.Error("failure expected in the first line, got", explanation)
+	}
+}
+
+// go test -v -test.run TestContainer_ExplainConsumes ...restful
+func TestContainer_ExplainConsumes(t *testing.T) {
+	httpRequest := httptest.NewRequest("PUT", "/users/42", strings.NewReader("{}"))
+	httpRequest.Header.Set(HEADER_ContentType, MIME_JSON)
+	explanation := newExplainContainer(CurlyRouter{}).Explain(httpRequest)
+	if ser, ok := explanation.Error.(ServiceError); !ok || 415 != ser.Code {
+		t.Error("415 expected, got", explanation.Error)
+	}
+	if got := routeRejections(explanation.WebServices[0])["updateUser"]; RejectedByConsumes != got {
+		t.Error("rejected by consumes expected, got", got)
+	}
+}
+
+// go test -v -test.run TestContainer_ExplainAccept ...restful
+func TestContainer_ExplainAccept(t *testing.T) {
+	httpRequest := httptest.NewRequest("GET", "/users/42", nil)
+	httpRequest.Header.Set(HEADER_Accept, MIME_XML)
+	explanation := newExplainContainer(CurlyRouter{}).Explain(httpRequest)
+	if ser, ok := explanation.Error.(ServiceError); !ok || 406 != ser.Code {
+		t.Error("406 expected, got", explanation.Error)
+	}
+	if got := routeRejections(explanation.WebServices[0])["findUser"]; RejectedByAccept != got {
+		t.Error("rejected by accept expected, got", got)
+	}
+}
+
+// go test -v -test.run TestContainer_ExplainHost ...restful
+func TestContainer_ExplainHost(t *testing.T) {
+	wc := newExplainContainer(CurlyRouter{})
+	admin := new(WebService).Path("/admin").Host("admin.example.com")
+	admin.Route(admin.GET("").To(dummy).Operation("admin"))
+	wc.Add(admin)
+	explanation := wc.Explain(httptest.NewRequest("GET", "http://www.example.com/admin", nil))
+	if got := explanation.WebServices[2].Rejection; RejectedByHost != got {
+		t.Error("rejected by host expected, got", got)
+	}
+	explanation = wc.Explain(httptest.NewRequest("GET", "http://admin.example.com/admin", nil))
+	if !explanation.WebServices[2].Selected {
+		t.Error("WebService of the host expected, got", explanation)
+	}
+}
+
+// go test -v -test.run TestContainer_ExplainApiVersion ...restful
+func TestContainer_ExplainApiVersion(t *testing.T) {
+	wc := NewContainer()
+	wc.ApiVersionNegotiation(ApiVersionNegotiation{Header: "Api-Version", Default: "1"})
+	wc.Add(newVersionedUserService("1"))
+	wc.Add(newVersionedUserService("2"))
+	httpRequest := httptest.NewRequest("GET", "/users/42", nil)
+	httpRequest.Header.Set("Api-Version", "2")
+	explanation := wc.Explain(httpRequest)
+	if RejectedByApiVersion != explanation.WebServices[0].Rejection || !explanation.WebServices[1].Selected {
+		t.Errorf("version 2 expected, got %v", explanation)
+	}
+	if !strings.Contains(explanation.String(), "WebService /users (version 1) rejected by api version") {
+		t.Error("version in explanation expected, got", explanation)
+	}
+}
+
+// go test -v -test.run TestContainer_ExplainAutoHead ...restful
+func TestContainer_ExplainAutoHead(t *testing.T) {
+	wc := newExplainContainer(CurlyRouter{})
+	wc.EnableAutoHead(true)
+	explanation := wc.Explain(httptest.NewRequest("HEAD", "/users/42", nil))
+	if explanation.Error != nil || explanation.Selected == nil || "findUser" != explanation.Selected.Operation {
+		t.Errorf("the GET Route expected, got %v", explanation)
+	}
+}
+
+// go test -v -test.run TestContainer_ExplainSameAsDispatch ...restful
+func TestContainer_ExplainSameAsDispatch(t *testing.T) {
+	wc := newExplainContainer(CurlyRouter{})
+	for _, each := range []struct{ method, url string }{
+		{"GET", "/users/42"},
+		{"DELETE", "/users/42"},
+		{"GET", "/customers/42"},
+		{"GET", "/orders/7"},
+		{"POST", "/orders/7"},
+	} {
+		code := 200
+		if ser, ok := wc.Explain(httptest.NewRequest(each.method, each.url, nil)).Error.(ServiceError); ok {
+			code = ser.Code
+		}
+		if httpWriter := dispatchRequest(wc, each.method, each.url, nil, ""); code != httpWriter.Code {
+			t.Errorf("%s %s: explained %d but dispatched %d", each.method, each.url, code, httpWriter.Code)
+		}
+	}
+}
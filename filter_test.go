@@ -4,6 +4,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -139,3 +140,67 @@ func sendItTo(address string, container *Container) string {
 	container.dispatch(httpWriter, httpRequest)
 	return httpWriter.Body.String()
 }
+
+// go test -v -test.run TestContainer_PreRoutingFilter ...restful
+func TestContainer_PreRoutingFilter(t *testing.T) {
+	wc := NewContainer()
+	wc.PreRoutingFilter(func(req *Request, resp *Response, chain *FilterChain) {
+		// strip the tenant prefix
+		if tenant, rest := splitTenant(req.Request.URL.Path); tenant != "" {
+			req.SetAttribute("tenant", tenant)
+			req.Request.URL.Path = rest
+		}
+		chain.ProcessFilter(req, resp)
+	})
+	wc.PreRoutingFilter(func(req *Request, resp *Response, chain *FilterChain) {
+		if req.Request.Header.Get("X-Block") != "" {
+			resp.WriteErrorString(http.StatusForbidden, "blocked")
+			return
+		}
+		if override := req.Request.Header.Get("X-HTTP-Method-Override"); override != "" {
+			req.Request.Method = override
+		}
+		chain.ProcessFilter(req, resp)
+	})
+	filterSawTenant := false
+	wc.Filter(func(req *Request, resp *Response, chain *FilterChain) {
+		filterSawTenant = req.Attribute("tenant") == "acme"
+		chain.ProcessFilter(req, resp)
+	})
+	ws := new(WebService).Path("/")
+	ws.Route(ws.DELETE("/users/{id}").To(func(req *Request, resp *Response) {
+		io.WriteString(resp, req.Attribute("tenant").(string)+":"+req.PathParameter("id"))
+	}).Operation("deleteUser"))
+	wc.Add(ws)
+
+	httpRequest, _ := http.NewRequest("POST", "/tenants/acme/users/42", nil)
+	httpRequest.Header.Set("X-HTTP-Method-Override", "DELETE")
+	httpWriter := httptest.NewRecorder()
+	wc.ServeHTTP(httpWriter, httpRequest)
+	if got, want := httpWriter.Body.String(), "acme:42"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if !filterSawTenant {
+		t.Error("expected container filter to see the tenant attribute")
+	}
+
+	httpRequest.Header.Set("X-Block", "true")
+	httpWriter = httptest.NewRecorder()
+	wc.ServeHTTP(httpWriter, httpRequest)
+	if got, want := httpWriter.Code, http.StatusForbidden; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+func splitTenant(path string) (string, string) {
+	const prefix = "/tenants/"
+	if !strings.HasPrefix(path, prefix) {
+		return "", path
+	}
+	rest := path[len(prefix):]
+	slash := strings.Index(rest, "/")
+	if slash == -1 {
+		return rest, "/"
+	}
+	return rest[:slash], rest[slash:]
+}
@@ -0,0 +1,124 @@
+package restful
+
+// Copyright 2024 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// hostExpression holds a compiled host pattern (RegExp) needed to match against
+// the Host of a Http request and to extract host parameter values.
+type hostExpression struct {
+	VarNames []string // the names of parameters (enclosed by {}) in the host
+	Matcher  *regexp.Regexp
+	Source   string // Host pattern as defined by the WebService
+}
+
+// newHostExpression creates a hostExpression from a pattern such as {tenant}.example.com.
+// A parameter matches a part of one label, unless it has an expression or constraint, e.g. {env:(dev|prod)}.api.example.com
+// Returns an error if the pattern is invalid.
+func newHostExpression(pattern string) (*hostExpression, error) {
+	var buffer bytes.Buffer
+	buffer.WriteString("(?i)^")
+	varNames := []string{}
+	remaining := pattern
+	for len(remaining) > 0 {
+		begin := strings.Index(remaining, "{")
+		if begin == -1 {
+			buffer.WriteString(regexp.QuoteMeta(remaining))
+			break
+		}
+		end := strings.Index(remaining[begin:], "}")
+		if end == -1 {
+			return nil, fmt.Errorf("missing closing } in host pattern:%s", pattern)
+		}
+		end += begin
+		buffer.WriteString(regexp.QuoteMeta(remaining[:begin]))
+		name, expression := splitPathParameterToken(remaining[begin : end+1])
+		if expression == "" {
+			expression = "[^.]+"
+		}
+		buffer.WriteString(fmt.Sprintf("((?:%s))", expression))
+		varNames = append(varNames, name)
+		remaining = remaining[end+1:]
+	}
+	buffer.WriteString("$")
+	compiled, err := regexp.Compile(buffer.String())
+	if err != nil {
+		return nil, err
+	}
+	return &hostExpression{VarNames: varNames, Matcher: compiled, Source: pattern}, nil
+}
+
+// extractParameters returns the host parameters and whether the host matches.
+func (h *hostExpression) extractParameters(host string) (map[string]string, bool) {
+	matches := h.Matcher.FindStringSubmatch(host)
+	if matches == nil {
+		return nil, false
+	}
+	params := map[string]string{}
+	for i, name := range h.VarNames {
+		if i+1 < len(matches) {
+			params[name] = matches[i+1]
+		}
+	}
+	return params, true
+}
+
+// hostWithoutPort returns the host part of the Host header value, e.g. api.example.com:8080 => api.example.com
+func hostWithoutPort(host string) string {
+	if colon := strings.LastIndex(host, ":"); colon != -1 && colon > strings.LastIndex(host, "]") {
+		return host[:colon]
+	}
+	return host
+}
+
+// selectWebServicesByHost returns the WebServices that can handle requests for the host.
+// WebServices without host patterns handle any host unless a WebService with a matching host pattern has the same root path.
+func selectWebServicesByHost(webServices []*WebService, host string) []*WebService {
+	hasHosts := false
+	for _, each := range webServices {
+		if len(each.hosts) > 0 {
+			hasHosts = true
+			break
+		}
+	}
+	if !hasHosts {
+		return webServices
+	}
+	host = hostWithoutPort(host)
+	matching := make([]*WebService, 0, len(webServices))
+	for _, each := range webServices {
+		if len(each.hosts) > 0 && each.matchesHost(host) {
+			matching = append(matching, each)
+		}
+	}
+	selected := make([]*WebService, 0, len(webServices))
+	for _, each := range webServices {
+		if len(each.hosts) > 0 {
+			if each.matchesHost(host) {
+				selected = append(selected, each)
+			}
+			continue
+		}
+		shadowed := false
+		for _, other := range matching {
+			if other.rootPath == each.rootPath {
+				shadowed = true
+				break
+			}
+		}
+		if !shadowed {
+			selected = append(selected, each)
+		}
+	}
+	if trace && len(selected) < len(webServices) {
+		traceLogger.Printf("%d of %d WebServices match host:%s\n", len(selected), len(webServices), host)
+	}
+	return selected
+}
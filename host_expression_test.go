@@ -0,0 +1,126 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var hostMatchers = []struct {
+	pattern string
+	host    string
+	matches bool
+	params  map[string]string
+}{
+	{"api.example.com", "api.example.com", true, map[string]string{}},
+	{"api.example.com", "API.Example.com", true, map[string]string{}},
+	{"api.example.com", "admin.example.com", false, nil},
+	{"api.example.com", "api.example.com.evil.com", false, nil},
+	{"{tenant}.example.com", "acme.example.com", true, map[string]string{"tenant": "acme"}},
+	{"{tenant}.example.com", "a.b.example.com", false, nil},
+	{"{tenant}.{region}.example.com", "acme.eu.example.com", true, map[string]string{"tenant": "acme", "region": "eu"}},
+	{"api-{env:(dev|prod)}.example.com", "api-dev.example.com", true, map[string]string{"env": "dev"}},
+	{"api-{env:(dev|prod)}.example.com", "api-test.example.com", false, nil},
+	{"{id:int}.example.com", "42.example.com", true, map[string]string{"id": "42"}},
+}
+
+// go test -v -test.run TestHostExpression ...restful
+func TestHostExpression(t *testing.T) {
+	for i, each := range hostMatchers {
+		expr, err := newHostExpression(each.pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		params, ok := expr.extractParameters(each.host)
+		if ok != each.matches {
+			t.Errorf("[%d] %s %s got %v want %v", i, each.pattern, each.host, ok, each.matches)
+			continue
+		}
+		if len(params) != len(each.params) {
+			t.Errorf("[%d] got %v want %v", i, params, each.params)
+		}
+		for k, v := range each.params {
+			if params[k] != v {
+				t.Errorf("[%d] got %v want %v", i, params, each.params)
+			}
+		}
+	}
+}
+
+func TestHostWithoutPort(t *testing.T) {
+	for host, want := range map[string]string{
+		"api.example.com":      "api.example.com",
+		"api.example.com:8080": "api.example.com",
+		"[::1]:8080":           "[::1]",
+		"[::1]":                "[::1]",
+	} {
+		if got := hostWithoutPort(host); got != want {
+			t.Errorf("got %v want %v", got, want)
+		}
+	}
+}
+
+// go test -v -test.run TestHostRouting_Routers ...restful
+func TestHostRouting_Routers(t *testing.T) {
+	api := new(WebService).Path("/").Host("api.example.com")
+	api.Route(api.GET("/info").To(dummy).Operation("api"))
+	admin := new(WebService).Path("/").Host("{tenant}.admin.example.com")
+	admin.Route(admin.GET("/info").To(dummy).Operation("admin"))
+	other := new(WebService).Path("/")
+	other.Route(other.GET("/info").To(dummy).Operation("other"))
+	services := []*WebService{api, admin, other}
+
+	routers := map[string]RouteSelector{"curly": CurlyRouter{}, "jsr311": RouterJSR311{}, "radix": NewRadixRouter()}
+	for name, router := range routers {
+		for host, operation := range map[string]string{
+			"api.example.com:8080":     "api",
+			"acme.admin.example.com":   "admin",
+			"www.example.com":          "other",
+			"acme.b.admin.example.com": "other",
+		} {
+			req, _ := http.NewRequest("GET", "http://"+host+"/info", nil)
+			_, route, err := router.SelectRoute(services, req)
+			if err != nil {
+				t.Fatalf("[%s] %s: %v", name, host, err)
+			}
+			if got, want := route.Operation, operation; got != want {
+				t.Errorf("[%s] %s: got %v want %v", name, host, got, want)
+			}
+		}
+	}
+}
+
+// go test -v -test.run TestHostRouting_Container ...restful
+func TestHostRouting_Container(t *testing.T) {
+	wc := NewContainer()
+	api := new(WebService).Path("/users").Host("{tenant}.example.com")
+	api.Param(api.HostParameter("tenant", "name of the tenant"))
+	api.Route(api.GET("/{id}").To(func(req *Request, resp *Response) {
+		resp.Write([]byte(req.PathParameter("tenant") + ":" + req.PathParameter("id")))
+	}).Operation("findUser"))
+	wc.Add(api)
+	other := new(WebService).Path("/users").Host("example.org")
+	other.Route(other.GET("/{id}").To(dummy))
+	wc.Add(other) // same root path for a different host is allowed
+
+	httpRequest, _ := http.NewRequest("GET", "http://acme.example.com/users/42", nil)
+	httpWriter := httptest.NewRecorder()
+	wc.ServeHTTP(httpWriter, httpRequest)
+	if got, want := httpWriter.Body.String(), "acme:42"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+
+	httpRequest, _ = http.NewRequest("GET", "http://example.net/users/42", nil)
+	httpWriter = httptest.NewRecorder()
+	wc.ServeHTTP(httpWriter, httpRequest)
+	if got, want := httpWriter.Code, http.StatusNotFound; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+
+	if got, want := api.PathParameters()[0].Kind(), HostParameterKind; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := len(wc.RouteConflicts()), 0; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
@@ -0,0 +1,452 @@
+package restful
+
+// Copyright 2024 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// JSONSchema is a (subset of a) JSON Schema that describes the JSON encoding of a Go type.
+type JSONSchema struct {
+	Type                 string                 `json:"type,omitempty"` // empty means any value
+	Format               string                 `json:"format,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Nullable             bool                   `json:"nullable,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Enum                 []interface{}          `json:"enum,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty"`
+	MinLength            *int64                 `json:"minLength,omitempty"`
+	MaxLength            *int64                 `json:"maxLength,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+}
+
+// jsonSchemas caches the JSONSchema per reflect.Type
+var jsonSchemas sync.Map
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// JSONSchemaOf returns the JSONSchema of the JSON encoding of the type of the sample, typically the sample passed to RouteBuilder.Reads.
+// Property names are taken from the json tag. Other struct tags that are used:
+//
+//	description:"the name of the user"
+//	required:"true"
+//	minimum:"0"  maximum:"150"       (numbers)
+//	minLength:"1"  maxLength:"64"    (strings)
+//	pattern:"^[a-z]+$"               (strings)
+//	enum:"red|green|blue"            (strings and numbers)
+//
+// Types that implement json.Marshaler (except time.Time) are accepted as any value. Fields with the string option
+// (e.g. `json:"id,string"`) are strings. Like encoding/json, property names of a document are matched case-insensitively.
+// The result is cached per type and must not be changed.
+func JSONSchemaOf(sample interface{}) *JSONSchema {
+	sampleType := reflect.TypeOf(sample)
+	if sampleType == nil {
+		return &JSONSchema{}
+	}
+	if cached, ok := jsonSchemas.Load(sampleType); ok {
+		return cached.(*JSONSchema)
+	}
+	schema := jsonSchemaOfType(sampleType, map[reflect.Type]bool{})
+	jsonSchemas.Store(sampleType, schema)
+	return schema
+}
+
+// jsonSchemaOfType returns the schema of the type ; types that are being visited are accepted as any value.
+func jsonSchemaOfType(t reflect.Type, visiting map[reflect.Type]bool) *JSONSchema {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+	schema := &JSONSchema{Nullable: nullable}
+	switch {
+	case t == timeType:
+		schema.Type, schema.Format = "string", "date-time"
+		return schema
+	case t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType):
+		return schema
+	case t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType):
+		schema.Type = "string"
+		return schema
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		schema.Type = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		schema.Type = "integer"
+	case reflect.Float32, reflect.Float64:
+		schema.Type = "number"
+	case reflect.String:
+		schema.Type = "string"
+	case reflect.Slice, reflect.Array:
+		// like encoding/json, only a byte slice is encoded as a base64 string ; a byte array is an array of numbers
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			schema.Type, schema.Format, schema.Nullable = "string", "byte", true
+			break
+		}
+		schema.Type = "array"
+		schema.Nullable = t.Kind() == reflect.Slice
+		schema.Items = jsonSchemaOfType(t.Elem(), visiting)
+	case reflect.Map:
+		schema.Type = "object"
+		schema.Nullable = true
+		schema.AdditionalProperties = jsonSchemaOfType(t.Elem(), visiting)
+	case reflect.Struct:
+		if visiting[t] {
+			// recursive type
+			return schema
+		}
+		visiting[t] = true
+		schema.Type = "object"
+		schema.Properties = map[string]*JSONSchema{}
+		addJSONSchemaProperties(schema, t, visiting)
+		delete(visiting, t)
+	case reflect.Interface:
+		schema.Nullable = true
+	}
+	return schema
+}
+
+// addJSONSchemaProperties adds the schema of each (embedded) exported field of the struct type to the properties of the schema.
+// Like encoding/json, a field of an embedded struct does not replace a less nested field with the same name.
+func addJSONSchemaProperties(schema *JSONSchema, t reflect.Type, visiting map[reflect.Type]bool) {
+	fields := []jsonSchemaField{}
+	collectJSONSchemaFields(t, 0, map[reflect.Type]bool{}, &fields)
+	for name, field := range dominantJSONSchemaFields(fields) {
+		tag := field.Tag.Get("json")
+		property := jsonSchemaOfType(field.Type, visiting)
+		if isQuotedJSONField(field.Type, tag) {
+			property = &JSONSchema{Type: "string", Nullable: property.Nullable}
+		}
+		applyJSONSchemaTags(property, field.Tag)
+		schema.Properties[name] = property
+		if field.Tag.Get("required") == "true" {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	sort.Strings(schema.Required)
+}
+
+// jsonSchemaField is a field of a struct or of one of its embedded structs, as encoded by encoding/json.
+type jsonSchemaField struct {
+	reflect.StructField
+	name   string // the JSON name
+	depth  int    // the number of embedded structs it is nested in
+	tagged bool   // whether the name is taken from the json tag
+}
+
+// collectJSONSchemaFields appends the fields of the struct type, and those of its embedded structs, in declaration order.
+func collectJSONSchemaFields(t reflect.Type, depth int, embedding map[reflect.Type]bool, fields *[]jsonSchemaField) {
+	embedding[t] = true
+	defer delete(embedding, t)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && len(name) == 0 {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if !embedding[embedded] {
+					collectJSONSchemaFields(embedded, depth+1, embedding, fields)
+				}
+				continue
+			}
+		}
+		if len(field.PkgPath) > 0 {
+			continue
+		}
+		tagged := len(name) > 0
+		if !tagged {
+			name = field.Name
+		}
+		*fields = append(*fields, jsonSchemaField{StructField: field, name: name, depth: depth, tagged: tagged})
+	}
+}
+
+// dominantJSONSchemaFields returns the field by name that encoding/json uses: the least nested field, or else the only tagged one.
+// A name for which this is ambiguous is omitted.
+func dominantJSONSchemaFields(fields []jsonSchemaField) map[string]reflect.StructField {
+	byName := map[string][]jsonSchemaField{}
+	for _, each := range fields {
+		byName[each.name] = append(byName[each.name], each)
+	}
+	dominant := map[string]reflect.StructField{}
+	for name, candidates := range byName {
+		depth := candidates[0].depth
+		for _, each := range candidates {
+			if each.depth < depth {
+				depth = each.depth
+			}
+		}
+		var shallowest, tagged []jsonSchemaField
+		for _, each := range candidates {
+			if each.depth != depth {
+				continue
+			}
+			shallowest = append(shallowest, each)
+			if each.tagged {
+				tagged = append(tagged, each)
+			}
+		}
+		switch {
+		case len(shallowest) == 1:
+			dominant[name] = shallowest[0].StructField
+		case len(tagged) == 1:
+			dominant[name] = tagged[0].StructField
+		}
+	}
+	return dominant
+}
+
+// isQuotedJSONField returns whether the field has the string option, e.g. `json:"id,string"`, that encodes its value as a JSON string.
+// Like encoding/json, the option only applies to booleans, numbers and strings.
+func isQuotedJSONField(t reflect.Type, tag string) bool {
+	quoted := false
+	for _, option := range strings.Split(tag, ",")[1:] {
+		quoted = quoted || option == "string"
+	}
+	if t.Name() == "" && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.String:
+		return quoted
+	}
+	return false
+}
+
+// applyJSONSchemaTags sets the constraints from the struct tags of a field ; invalid values are ignored.
+func applyJSONSchemaTags(schema *JSONSchema, tag reflect.StructTag) {
+	schema.Description = tag.Get("description")
+	schema.Pattern = tag.Get("pattern")
+	if value, err := strconv.ParseFloat(tag.Get("minimum"), 64); err == nil {
+		schema.Minimum = &value
+	}
+	if value, err := strconv.ParseFloat(tag.Get("maximum"), 64); err == nil {
+		schema.Maximum = &value
+	}
+	if value, err := strconv.ParseInt(tag.Get("minLength"), 10, 64); err == nil {
+		schema.MinLength = &value
+	}
+	if value, err := strconv.ParseInt(tag.Get("maxLength"), 10, 64); err == nil {
+		schema.MaxLength = &value
+	}
+	if enum := tag.Get("enum"); len(enum) > 0 {
+		for _, each := range strings.Split(enum, "|") {
+			if schema.Type == "integer" || schema.Type == "number" {
+				if number, err := strconv.ParseFloat(each, 64); err == nil {
+					schema.Enum = append(schema.Enum, number)
+				}
+				continue
+			}
+			schema.Enum = append(schema.Enum, each)
+		}
+	}
+}
+
+// Validate checks the JSON document against the schema and returns a problem for each invalid value,
+// prefixed by its JSON pointer in URI fragment notation, e.g. `#/owner/id: expected integer, got string`.
+func (s *JSONSchema) Validate(document []byte) []string {
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return []string{fmt.Sprintf("#: invalid JSON: %v", err)}
+	}
+	problems := []string{}
+	s.validate(value, "#", &problems)
+	return problems
+}
+
+func (s *JSONSchema) validate(value interface{}, pointer string, problems *[]string) {
+	if value == nil {
+		if !s.Nullable && len(s.Type) > 0 {
+			*problems = append(*problems, fmt.Sprintf("%s: expected %s, got null", pointer, s.Type))
+		}
+		return
+	}
+	if len(s.Type) == 0 {
+		return
+	}
+	switch value := value.(type) {
+	case map[string]interface{}:
+		if s.Type != "object" {
+			break
+		}
+		names := make([]string, 0, len(value))
+		present := map[string]bool{}
+		for name := range value {
+			names = append(names, name)
+			if propertyName, ok := s.propertyName(name); ok {
+				present[propertyName] = true
+			}
+		}
+		for _, name := range s.Required {
+			if !present[name] {
+				*problems = append(*problems, fmt.Sprintf("%s: missing required property", pointer+"/"+escapeJSONPointer(name)))
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property := s.AdditionalProperties
+			if propertyName, ok := s.propertyName(name); ok {
+				property = s.Properties[propertyName]
+			}
+			if property != nil {
+				property.validate(value[name], pointer+"/"+escapeJSONPointer(name), problems)
+			}
+		}
+		return
+	case []interface{}:
+		if s.Type != "array" {
+			break
+		}
+		for i, each := range value {
+			s.Items.validate(each, pointer+"/"+strconv.Itoa(i), problems)
+		}
+		return
+	case string:
+		if s.Type != "string" {
+			break
+		}
+		s.validateString(value, pointer, problems)
+		return
+	case json.Number:
+		if s.Type != "number" && s.Type != "integer" {
+			break
+		}
+		s.validateNumber(value, pointer, problems)
+		return
+	case bool:
+		if s.Type == "boolean" {
+			return
+		}
+	}
+	*problems = append(*problems, fmt.Sprintf("%s: expected %s, got %s", pointer, s.Type, jsonTypeName(value)))
+}
+
+// propertyName returns the name of the property for the key of a JSON object.
+// Like encoding/json, an exact match is preferred over a case-insensitive match.
+func (s *JSONSchema) propertyName(key string) (string, bool) {
+	if _, ok := s.Properties[key]; ok {
+		return key, true
+	}
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		if strings.EqualFold(name, key) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "", false
+	}
+	sort.Strings(names)
+	return names[0], true
+}
+
+func (s *JSONSchema) validateString(value, pointer string, problems *[]string) {
+	length := int64(utf8.RuneCountInString(value))
+	if s.MinLength != nil && length < *s.MinLength {
+		*problems = append(*problems, fmt.Sprintf("%s: length %d is less than minimum length %d", pointer, length, *s.MinLength))
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		*problems = append(*problems, fmt.Sprintf("%s: length %d is greater than maximum length %d", pointer, length, *s.MaxLength))
+	}
+	if len(s.Pattern) > 0 {
+		if matcher, err := compiledParameterPattern(s.Pattern); err == nil && !matcher.MatchString(value) {
+			*problems = append(*problems, fmt.Sprintf("%s: value %q does not match pattern %s", pointer, value, s.Pattern))
+		}
+	}
+	if len(s.Enum) > 0 {
+		for _, each := range s.Enum {
+			if each == value {
+				return
+			}
+		}
+		*problems = append(*problems, fmt.Sprintf("%s: value %q is not one of %s", pointer, value, formatEnum(s.Enum)))
+	}
+}
+
+func (s *JSONSchema) validateNumber(value json.Number, pointer string, problems *[]string) {
+	number, err := value.Float64()
+	if err != nil {
+		*problems = append(*problems, fmt.Sprintf("%s: invalid number %s", pointer, value))
+		return
+	}
+	if s.Type == "integer" && number != math.Trunc(number) {
+		*problems = append(*problems, fmt.Sprintf("%s: expected integer, got %s", pointer, value))
+		return
+	}
+	if s.Minimum != nil && number < *s.Minimum {
+		*problems = append(*problems, fmt.Sprintf("%s: value %s is less than minimum %v", pointer, value, *s.Minimum))
+	}
+	if s.Maximum != nil && number > *s.Maximum {
+		*problems = append(*problems, fmt.Sprintf("%s: value %s is greater than maximum %v", pointer, value, *s.Maximum))
+	}
+	if len(s.Enum) > 0 {
+		for _, each := range s.Enum {
+			if each == number {
+				return
+			}
+		}
+		*problems = append(*problems, fmt.Sprintf("%s: value %s is not one of %s", pointer, value, formatEnum(s.Enum)))
+	}
+}
+
+func formatEnum(enum []interface{}) string {
+	values := make([]string, len(enum))
+	for i, each := range enum {
+		values[i] = fmt.Sprint(each)
+	}
+	return strings.Join(values, ", ")
+}
+
+// jsonTypeName returns the JSON Schema type name of a decoded JSON value.
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return "null"
+}
+
+// escapeJSONPointer escapes a property name for use in a JSON pointer (RFC 6901).
+func escapeJSONPointer(name string) string {
+	return strings.Replace(strings.Replace(name, "~", "~0", -1), "/", "~1", -1)
+}
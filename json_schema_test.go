@@ -0,0 +1,204 @@
+package restful
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+type schemaAddress struct {
+	City string `json:"city" required:"true"`
+}
+
+type schemaBase struct {
+	ID string `json:"id,omitempty"`
+}
+
+type schemaUser struct {
+	schemaBase
+	Name     string          `json:"name" required:"true" description:"full name" minLength:"2" maxLength:"8" pattern:"^[A-Za-z ]+$"`
+	Age      int             `json:"age" minimum:"0" maximum:"150"`
+	Score    float64         `json:"score,omitempty"`
+	Color    string          `json:"color,omitempty" enum:"red|green"`
+	Level    int             `json:"level,omitempty" enum:"1|2|3"`
+	Active   bool            `json:"active"`
+	Born     time.Time       `json:"born"`
+	Address  *schemaAddress  `json:"address,omitempty"`
+	Tags     []string        `json:"tags"`
+	Labels   map[string]int  `json:"labels"`
+	Any      interface{}     `json:"any"`
+	Raw      json.RawMessage `json:"raw"`
+	Friends  []*schemaUser   `json:"friends"`
+	Secret   string          `json:"-"`
+	internal string
+}
+
+// go test -v -test.run TestJSONSchemaOf ...restful
+func TestJSONSchemaOf(t *testing.T) {
+	schema := JSONSchemaOf(schemaUser{})
+	if got, want := schema.Type, "object"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := strings.Join(schema.Required, ","), "name"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	for name, want := range map[string]string{
+		"id": "string", "name": "string", "age": "integer", "score": "number", "active": "boolean",
+		"born": "string", "address": "object", "tags": "array", "labels": "object", "any": "", "raw": "", "friends": "array",
+	} {
+		property, ok := schema.Properties[name]
+		if !ok {
+			t.Errorf("missing property %s", name)
+			continue
+		}
+		if property.Type != want {
+			t.Errorf("%s: got %v want %v", name, property.Type, want)
+		}
+	}
+	for _, each := range []string{"Secret", "internal", "schemaBase"} {
+		if _, ok := schema.Properties[each]; ok {
+			t.Errorf("unexpected property %s", each)
+		}
+	}
+	if got, want := schema.Properties["name"].Description, "full name"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := schema.Properties["born"].Format, "date-time"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if schema.Properties["friends"].Items.Items != nil || schema.Properties["friends"].Items.Type != "" {
+		t.Error("recursive type must be accepted as any value")
+	}
+	if JSONSchemaOf(&schemaUser{}) != JSONSchemaOf(&schemaUser{}) {
+		t.Error("expected cached schema")
+	}
+}
+
+// go test -v -test.run TestJSONSchema_Validate ...restful
+func TestJSONSchema_Validate(t *testing.T) {
+	schema := JSONSchemaOf(schemaUser{})
+	tests := []struct {
+		document string
+		problems []string
+	}{
+		{`{"name":"John","age":42,"born":"2000-01-01T00:00:00Z","address":{"city":"Amsterdam"},"tags":["a"],"labels":{"x":1},"any":[1],"raw":{"a":1},"unknown":true}`, nil},
+		{`{"name":"John","age":1.5,"address":null,"tags":null,"friends":[{"age":"recursive types are not checked"}]}`, []string{"#/age: expected integer, got 1.5"}},
+		{`{"age":200}`, []string{"#/name: missing required property", "#/age: value 200 is greater than maximum 150"}},
+		{`{"name":"J1","color":"blue","level":4}`, []string{`#/color: value "blue" is not one of red, green`, "#/level: value 4 is not one of 1, 2, 3", `#/name: value "J1" does not match pattern ^[A-Za-z ]+$`}},
+		{`{"name":"Johnathan Doe","active":"yes","tags":[1],"labels":{"x":"1"},"address":{}}`, []string{
+			"#/active: expected boolean, got string",
+			"#/address/city: missing required property",
+			"#/labels/x: expected integer, got string",
+			"#/name: length 13 is greater than maximum length 8",
+			"#/tags/0: expected string, got number"}},
+		{`{"name":null}`, []string{"#/name: expected string, got null"}},
+		{`[]`, []string{"#: expected object, got array"}},
+		{`{"name":`, []string{"#: invalid JSON: unexpected EOF"}},
+	}
+	for _, tt := range tests {
+		problems := schema.Validate([]byte(tt.document))
+		if got, want := strings.Join(problems, "\n"), strings.Join(tt.problems, "\n"); got != want {
+			t.Errorf("%s\ngot:\n%s\nwant:\n%s", tt.document, got, want)
+		}
+	}
+}
+
+type schemaCounter struct {
+	Name  string   `json:"name" required:"true"`
+	Count int      `json:"count,string"`
+	Ratio *float64 `json:"ratio,string"`
+}
+
+// go test -v -test.run TestJSONSchema_ValidateLikeDecoder ...restful
+func TestJSONSchema_ValidateLikeDecoder(t *testing.T) {
+	schema := JSONSchemaOf(schemaCounter{})
+	tests := []struct {
+		document string
+		problems []string
+	}{
+		{`{"Name":"x","COUNT":"5","ratio":"0.5"}`, []string{}},
+		{`{"name":"x","count":5}`, []string{"#/count: expected string, got number"}},
+		{`{"nam":"x"}`, []string{"#/name: missing required property"}},
+	}
+	for _, tt := range tests {
+		problems := schema.Validate([]byte(tt.document))
+		if got, want := strings.Join(problems, "\n"), strings.Join(tt.problems, "\n"); got != want {
+			t.Errorf("%s\ngot:\n%s\nwant:\n%s", tt.document, got, want)
+		}
+		// the decoder accepts the valid documents
+		if len(tt.problems) == 0 {
+			if err := json.Unmarshal([]byte(tt.document), new(schemaCounter)); err != nil {
+				t.Errorf("%s: %v", tt.document, err)
+			}
+		}
+	}
+}
+
+type schemaDigest struct {
+	Hash [4]byte `json:"hash"`
+	Data []byte  `json:"data"`
+}
+
+// go test -v -test.run TestJSONSchemaOf_ByteArray ...restful
+func TestJSONSchemaOf_ByteArray(t *testing.T) {
+	schema := JSONSchemaOf(schemaDigest{})
+	if hash := schema.Properties["hash"]; hash.Type != "array" || hash.Items.Type != "integer" {
+		t.Errorf("byte array must be an array of integers like encoding/json, got %s of %v", hash.Type, hash.Items)
+	}
+	if data := schema.Properties["data"]; data.Type != "string" || data.Format != "byte" {
+		t.Errorf("byte slice must be a base64 string, got %s (%s)", data.Type, data.Format)
+	}
+	document, _ := json.Marshal(schemaDigest{Hash: [4]byte{1, 2, 3, 4}, Data: []byte{1, 2}})
+	if problems := schema.Validate(document); len(problems) != 0 {
+		t.Errorf("%s: unexpected problems %v", document, problems)
+	}
+}
+
+type schemaAudit struct {
+	Name    int    `json:"name"`
+	Created string `json:"created"`
+	Version int
+}
+
+type schemaRevision struct {
+	Version string
+}
+
+type schemaDocument struct {
+	Name string `json:"name"`
+	schemaAudit
+	*schemaRevision
+	Title string
+}
+
+type schemaTaggedRevision struct {
+	Version int `json:"Version"`
+}
+
+type schemaTaggedDocument struct {
+	schemaRevision
+	schemaTaggedRevision
+}
+
+// go test -v -test.run TestJSONSchemaOf_EmbeddedFieldPrecedence ...restful
+func TestJSONSchemaOf_EmbeddedFieldPrecedence(t *testing.T) {
+	schema := JSONSchemaOf(schemaDocument{})
+	if got, want := schema.Properties["name"].Type, "string"; got != want {
+		t.Errorf("the less nested field must win although embedded later, got %v want %v", got, want)
+	}
+	if got, want := schema.Properties["created"].Type, "string"; got != want {
+		t.Errorf("promoted field expected, got %v want %v", got, want)
+	}
+	if _, ok := schema.Properties["Version"]; ok {
+		t.Error("ambiguous fields at the same depth must be omitted like encoding/json")
+	}
+	if problems := schema.Validate([]byte(`{"name":"report","created":"today","Version":"any"}`)); len(problems) != 0 {
+		t.Error("unexpected problems", problems)
+	}
+
+	tagged := JSONSchemaOf(schemaTaggedDocument{})
+	if got, want := tagged.Properties["Version"].Type, "integer"; got != want {
+		t.Errorf("the tagged field must win at the same depth, got %v want %v", got, want)
+	}
+}
@@ -24,10 +24,11 @@ func (r RouterJSR311) SelectRoute(
 	webServices []*WebService,
 	httpRequest *http.Request) (selectedService *WebService, selectedRoute *Route, err error) {
 
-	// Identify the root resource class (WebService)
+	// Identify the root resource class (WebService) among those for the requested host
+	webServices = selectWebServicesByHost(webServices, httpRequest.Host)
 	dispatcher, finalMatch, err := r.detectDispatcher(httpRequest.URL.Path, webServices)
 	if err != nil {
-		return nil, nil, NewError(http.StatusNotFound, "")
+		return nil, nil, NewError(http.StatusNotFound, "404: Page Not Found")
 	}
 	// Obtain the set of candidate methods (Routes)
 	routes := r.selectRoutes(dispatcher, finalMatch)
@@ -66,17 +67,13 @@ func (RouterJSR311) extractParams(pathExpr *pathExpression, matches []string) ma
 }
 
 // http://jsr311.java.net/nonav/releases/1.1/spec/spec3.html#x3-360003.7.2
+// All routes match the request path. If no route passes its conditions then 404 (Not Found) is returned.
+// If none of the routes that pass their conditions has the request method then 405 (Method Not Allowed)
+// is returned with an Allow header listing the methods of those routes.
 func (r RouterJSR311) detectRoute(routes []Route, httpRequest *http.Request) (*Route, error) {
 	candidates := make([]*Route, 0, 8)
 	for i, each := range routes {
-		ok := true
-		for _, fn := range each.If {
-			if !fn(httpRequest) {
-				ok = false
-				break
-			}
-		}
-		if ok {
+		if each.passesConditions(httpRequest) {
 			candidates = append(candidates, &routes[i])
 		}
 	}
@@ -99,18 +96,8 @@ func (r RouterJSR311) detectRoute(routes []Route, httpRequest *http.Request) (*R
 		if trace {
 			traceLogger.Printf("no Route found (in %d routes) that matches HTTP method %s\n", len(previous), httpRequest.Method)
 		}
-		allowed := []string{}
-	allowedLoop:
-		for _, candidate := range previous {
-			for _, method := range allowed {
-				if method == candidate.Method {
-					continue allowedLoop
-				}
-			}
-			allowed = append(allowed, candidate.Method)
-		}
-		header := http.Header{"Allow": []string{strings.Join(allowed, ", ")}}
-		return nil, NewErrorWithHeader(http.StatusMethodNotAllowed, "405: Method Not Allowed", header)
+		// only the methods of the Routes that pass their conditions are allowed
+		return nil, newMethodNotAllowedError(allowedMethods(previous))
 	}
 
 	// content-type
@@ -0,0 +1,142 @@
+package restful
+
+// Copyright 2024 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// EnableMatrixParameters (default=false) controls whether matrix parameters in the segments of a request path
+// (e.g. /cars;color=red/models;year=2020) are ignored when selecting a Route and extracting path parameters.
+// Use Request.MatrixParameter to access their values.
+func (c *Container) EnableMatrixParameters(enabled bool) {
+	c.matrixParametersEnabled = enabled
+}
+
+// withoutMatrixParameters returns the path without the matrix parameters of its segments,
+// e.g. /cars;color=red/models;year=2020 => /cars/models
+func withoutMatrixParameters(path string) string {
+	if !strings.Contains(path, ";") {
+		return path
+	}
+	segments := strings.Split(path, "/")
+	for i, each := range segments {
+		if semi := strings.Index(each, ";"); semi != -1 {
+			segments[i] = each[:semi]
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// withoutMatrixParameters returns a shallow copy of the request with the matrix parameters removed from its path,
+// if enabled. An escaped semicolon (%3B) is not a separator of matrix parameters.
+func (c *Container) withoutMatrixParameters(httpRequest *http.Request) *http.Request {
+	if !c.matrixParametersEnabled {
+		return httpRequest
+	}
+	escapedPath := httpRequest.URL.EscapedPath()
+	if !strings.Contains(escapedPath, ";") {
+		return httpRequest
+	}
+	escapedPath = withoutMatrixParameters(escapedPath)
+	unescapedPath, err := url.PathUnescape(escapedPath)
+	if err != nil {
+		return withURLPath(httpRequest, withoutMatrixParameters(httpRequest.URL.Path))
+	}
+	copied := new(http.Request)
+	*copied = *httpRequest
+	copiedURL := *httpRequest.URL
+	copiedURL.Path = unescapedPath
+	copiedURL.RawPath = escapedPath
+	copied.URL = &copiedURL
+	return copied
+}
+
+// matrixParametersOf returns the decoded matrix parameters for each token of the request path, as tokenized by the router.
+// The path is cleaned first if the PathPolicy says so, such that the tokens are those of the path that selected the Route.
+func (c *Container) matrixParametersOf(httpRequest *http.Request) []map[string][]string {
+	requestPath := httpRequest.URL.EscapedPath()
+	if c.pathPolicy != nil && c.pathPolicy.CleanPath {
+		requestPath = cleanPath(requestPath)
+	}
+	tokens := tokenizePath(requestPath)
+	params := make([]map[string][]string, len(tokens))
+	for i, each := range tokens {
+		parts := strings.Split(each, ";")
+		if len(parts) == 1 {
+			continue
+		}
+		params[i] = map[string][]string{}
+		for _, param := range parts[1:] {
+			keyValue := strings.SplitN(param, "=", 2)
+			name, value := unescapedMatrixToken(keyValue[0]), ""
+			if len(keyValue) == 2 {
+				value = unescapedMatrixToken(keyValue[1])
+			}
+			params[i][name] = append(params[i][name], value)
+		}
+	}
+	return params
+}
+
+// unescapedMatrixToken returns the percent-decoded name or value, or the token itself if it is not validly encoded.
+func unescapedMatrixToken(token string) string {
+	if unescaped, err := url.PathUnescape(token); err == nil {
+		return unescaped
+	}
+	return token
+}
+
+// MatrixParameter returns the value of the matrix parameter in the path segment of the selected Route.
+// The segment is either a static element of the Route path (e.g. "cars") or a path parameter (e.g. "{id}").
+//
+//	// GET /cars;color=red/{model} with URL path /cars;color=red/beetle;year=1970
+//	req.MatrixParameter("cars", "color")    // red
+//	req.MatrixParameter("{model}", "year")  // 1970
+//
+// Returns an empty string if the parameter is absent or matrix parameters are not enabled for the Container.
+func (r *Request) MatrixParameter(segment, name string) string {
+	values := r.MatrixParameters(segment)[name]
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// MatrixParameters returns all the (decoded) matrix parameter values by name in the path segment of the selected Route.
+// See MatrixParameter for the format of the segment.
+func (r *Request) MatrixParameters(segment string) map[string][]string {
+	params := map[string][]string{}
+	if r.selectedRoute == nil {
+		return params
+	}
+	for i, each := range r.selectedRoute.pathParts {
+		if i == len(r.matrixParameters) {
+			break
+		}
+		if !matchesMatrixSegment(each, segment) {
+			continue
+		}
+		for name, values := range r.matrixParameters[i] {
+			params[name] = values
+		}
+		break
+	}
+	return params
+}
+
+// matchesMatrixSegment returns whether the token of a Route path is the segment, e.g. "cars" or "{model}" for {model:[a-z]+}
+func matchesMatrixSegment(routeToken, segment string) bool {
+	if routeToken == segment {
+		return true
+	}
+	if !strings.HasPrefix(routeToken, "{") || !strings.HasPrefix(segment, "{") {
+		return false
+	}
+	name, _ := splitPathParameterToken(routeToken)
+	return "{"+name+"}" == segment
+}
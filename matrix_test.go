@@ -0,0 +1,102 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithoutMatrixParameters(t *testing.T) {
+	for in, want := range map[string]string{
+		"/cars":                            "/cars",
+		"/cars;color=red":                  "/cars",
+		"/cars;color=red/models;year=2020": "/cars/models",
+		"/cars;/models;a;b=c/":             "/cars/models/",
+	} {
+		if got := withoutMatrixParameters(in); got != want {
+			t.Errorf("got %v want %v", got, want)
+		}
+	}
+}
+
+// go test -v -test.run TestMatrixParameters ...restful
+func TestMatrixParameters(t *testing.T) {
+	routers := map[string]RouteSelector{"curly": CurlyRouter{}, "jsr311": RouterJSR311{}, "radix": NewRadixRouter()}
+	for name, router := range routers {
+		wc := NewContainer()
+		wc.Router(router)
+		wc.EnableMatrixParameters(true)
+		ws := new(WebService).Path("/cars")
+		ws.Route(ws.GET("/{model}").
+			Param(ws.MatrixParameter("color", "color of the car")).
+			Param(ws.MatrixParameter("year", "year of the model")).
+			To(func(req *Request, resp *Response) {
+				resp.Write([]byte(req.PathParameter("model") + "," +
+					req.MatrixParameter("cars", "color") + "," +
+					req.MatrixParameter("{model}", "year") + "," +
+					req.MatrixParameter("{model}", "color")))
+			}).Operation("findModel"))
+		wc.Add(ws)
+
+		httpRequest, _ := http.NewRequest("GET", "/cars;color=red/beetle;year=1970", nil)
+		httpWriter := httptest.NewRecorder()
+		wc.ServeHTTP(httpWriter, httpRequest)
+		if got, want := httpWriter.Body.String(), "beetle,red,1970,"; got != want {
+			t.Errorf("[%s] got %v want %v", name, got, want)
+		}
+		httpRequest, _ = http.NewRequest("OPTIONS", "/cars;color=red/beetle;year=1970", nil)
+		if got, want := len(wc.computeAllowedMethods(NewRequest(httpRequest))), 1; got != want {
+			t.Errorf("[%s] got %v want %v", name, got, want)
+		}
+		if got, want := ws.Routes()[0].ParameterDocs[0].Kind(), MatrixParameterKind; got != want {
+			t.Errorf("got %v want %v", got, want)
+		}
+	}
+}
+
+// go test -v -test.run TestMatrixParameters_Disabled ...restful
+func TestMatrixParameters_Disabled(t *testing.T) {
+	wc := NewContainer()
+	ws := new(WebService).Path("/cars")
+	ws.Route(ws.GET("/{model}").To(func(req *Request, resp *Response) {
+		resp.Write([]byte(req.PathParameter("model") + "," + req.MatrixParameter("{model}", "year")))
+	}).Operation("findModel"))
+	wc.Add(ws)
+	httpRequest, _ := http.NewRequest("GET", "/cars/beetle;year=1970", nil)
+	httpWriter := httptest.NewRecorder()
+	wc.Dispatch(httpWriter, httpRequest)
+	if got, want := httpWriter.Body.String(), "beetle;year=1970,"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestMatrixParameters_PathPolicy ...restful
+func TestMatrixParameters_PathPolicy(t *testing.T) {
+	wc := NewContainer()
+	wc.EnableMatrixParameters(true)
+	wc.PathPolicy(PathPolicy{CleanPath: true, TrailingSlash: TrailingSlashLenient})
+	ws := new(WebService).Path("/cars")
+	ws.Route(ws.GET("/{model}/parts").To(func(req *Request, resp *Response) {
+		resp.Write([]byte(req.PathParameter("model") + "," +
+			req.MatrixParameter("cars", "color") + "," +
+			req.MatrixParameter("{model}", "year") + "," +
+			req.MatrixParameter("parts", "kind")))
+	}).Operation("findParts"))
+	wc.Add(ws)
+	for _, each := range []struct {
+		path string
+		body string
+	}{
+		{"/cars;color=dark%20red/beetle;year=1970/parts;kind=a%3Bb", "beetle,dark red,1970,a;b"},
+		{"/cars;color=red/./old;year=1/../beetle;year=1970/parts/", "beetle,red,1970,"},
+		{"/cars;color=red//beetle;year=1970/parts", "beetle,red,1970,"},
+	} {
+		httpRequest, _ := http.NewRequest("GET", each.path, nil)
+		httpWriter := httptest.NewRecorder()
+		// the ServeMux would redirect paths that are not clean
+		wc.Dispatch(httpWriter, httpRequest)
+		if got, want := httpWriter.Body.String(), each.body; got != want {
+			t.Errorf("%s: got %q want %q", each.path, got, want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package restful
+
+// Copyright 2024 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import "strings"
+
+// mediaType is a parsed media type, e.g. application/vnd.acme.order+json; version=2
+type mediaType struct {
+	mainType   string            // lowercase, e.g. application or *
+	subType    string            // lowercase, e.g. vnd.acme.order+json or *
+	suffix     string            // structured syntax suffix (RFC 6838), e.g. json
+	parameters map[string]string // lowercase names, without the quality (q) parameter
+}
+
+// parseMediaType returns the parsed media type ; a missing subtype is handled as a wildcard.
+func parseMediaType(value string) mediaType {
+	parts := strings.Split(value, ";")
+	typeAndSubtype := strings.SplitN(strings.ToLower(strings.TrimSpace(parts[0])), "/", 2)
+	parsed := mediaType{mainType: typeAndSubtype[0], subType: "*"}
+	if len(typeAndSubtype) == 2 {
+		parsed.subType = typeAndSubtype[1]
+	}
+	if plus := strings.LastIndex(parsed.subType, "+"); plus != -1 {
+		parsed.suffix = parsed.subType[plus+1:]
+	}
+	for _, each := range parts[1:] {
+		nameValue := strings.SplitN(each, "=", 2)
+		name := strings.ToLower(strings.TrimSpace(nameValue[0]))
+		if len(nameValue) != 2 || len(name) == 0 || name == qFactorWeightingKey {
+			continue
+		}
+		if parsed.parameters == nil {
+			parsed.parameters = map[string]string{}
+		}
+		parsed.parameters[name] = strings.Trim(strings.TrimSpace(nameValue[1]), `"`)
+	}
+	return parsed
+}
+
+// String returns the type and subtype without parameters.
+func (m mediaType) String() string {
+	return m.mainType + "/" + m.subType
+}
+
+// hasWildcard returns whether the type or subtype is a wildcard, e.g. */* or application/*
+func (m mediaType) hasWildcard() bool {
+	return m.mainType == "*" || m.subType == "*"
+}
+
+// matches returns whether the media types are compatible. Either one can have a wildcard type or subtype.
+// Parameters only conflict if both media types have the parameter with a different value, e.g. version=1 and version=2.
+// The value of the charset parameter is compared case-insensitive.
+func (m mediaType) matches(other mediaType) bool {
+	if m.mainType != "*" && other.mainType != "*" && m.mainType != other.mainType {
+		return false
+	}
+	if m.subType != "*" && other.subType != "*" && m.subType != other.subType {
+		return false
+	}
+	for name, value := range m.parameters {
+		otherValue, ok := other.parameters[name]
+		if !ok {
+			continue
+		}
+		if name == "charset" {
+			if !strings.EqualFold(value, otherValue) {
+				return false
+			}
+		} else if value != otherValue {
+			return false
+		}
+	}
+	return true
+}
+
+// withContentType returns the accessor that writes the precise media type, e.g. application/vnd.acme.order+json,
+// if it is a JSON or XML accessor found using the structured syntax suffix or if the media type has parameters.
+func withContentType(accessor EntityReaderWriter, contentType string) EntityReaderWriter {
+	precise := parseMediaType(contentType)
+	switch each := accessor.(type) {
+	case entityJSONAccess:
+		if precise.String() != parseMediaType(each.ContentType).String() || len(precise.parameters) > 0 {
+			each.ContentType = contentType
+		}
+		return each
+	case entityXMLAccess:
+		if precise.String() != parseMediaType(each.ContentType).String() || len(precise.parameters) > 0 {
+			each.ContentType = contentType
+		}
+		return each
+	}
+	return accessor
+}
@@ -0,0 +1,204 @@
+package restful
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type mediaTypeOrder struct {
+	ID   string `json:"id"`
+	Item string `json:"item"`
+}
+
+func newMediaTypeContainer() *Container {
+	wc := NewContainer()
+	ws := new(WebService).Path("/")
+	ws.Route(ws.GET("/orders").
+		Produces("application/vnd.acme.order+json", "application/vnd.acme.order+xml").
+		To(func(req *Request, resp *Response) {
+			resp.WriteEntity(mediaTypeOrder{ID: "1", Item: "book"})
+		}).Operation("getOrder"))
+	ws.Route(ws.GET("/v2/orders").
+		Produces("application/json;version=2").
+		To(func(req *Request, resp *Response) {
+			resp.WriteEntity(mediaTypeOrder{ID: "2"})
+		}).Operation("getOrderV2"))
+	ws.Route(ws.POST("/orders").
+		Consumes("application/vnd.acme.order+json").
+		To(func(req *Request, resp *Response) {
+			order := mediaTypeOrder{}
+			if err := req.ReadEntity(&order); err != nil {
+				resp.WriteErrorString(http.StatusBadRequest, err.Error())
+				return
+			}
+			io.WriteString(resp, order.Item)
+		}).Operation("createOrder"))
+	wc.Add(ws)
+	return wc
+}
+
+func acceptHeader(accept string) http.Header {
+	return http.Header{HEADER_Accept: {accept}}
+}
+
+// go test -v -test.run TestMediaType_SuffixJSON ...restful
+func TestMediaType_SuffixJSON(t *testing.T) {
+	httpWriter := dispatchRequest(newMediaTypeContainer(), "GET", "/orders", acceptHeader("application/vnd.acme.order+json"), "")
+	if "application/vnd.acme.order+json" != httpWriter.Header().Get(HEADER_ContentType) {
+		t.Error("the vendor media type expected as Content-Type, got", httpWriter.Header().Get(HEADER_ContentType))
+	}
+	if !strings.Contains(httpWriter.Body.String(), `"item": "book"`) {
+		t.Error("JSON expected, got", httpWriter.Body.String())
+	}
+}
+
+// go test -v -test.run TestMediaType_SuffixXML ...restful
+func TestMediaType_SuffixXML(t *testing.T) {
+	httpWriter := dispatchRequest(newMediaTypeContainer(), "GET", "/orders", acceptHeader("application/vnd.acme.order+xml"), "")
+	if "application/vnd.acme.order+xml" != httpWriter.Header().Get(HEADER_ContentType) {
+		t.Error("the vendor media type expected as Content-Type, got", httpWriter.Header().Get(HEADER_ContentType))
+	}
+	if !strings.Contains(httpWriter.Body.String(), "<Item>book</Item>") {
+		t.Error("XML expected, got", httpWriter.Body.String())
+	}
+}
+
+// go test -v -test.run TestMediaType_SubtypeWildcard ...restful
+func TestMediaType_SubtypeWildcard(t *testing.T) {
+	httpWriter := dispatchRequest(newMediaTypeContainer(), "GET", "/orders", acceptHeader("application/*"), "")
+	if "application/vnd.acme.order+json" != httpWriter.Header().Get(HEADER_ContentType) {
+		t.Error("the first produced media type expected, got", httpWriter.Header().Get(HEADER_ContentType))
+	}
+}
+
+// go test -v -test.run TestMediaType_Quality ...restful
+func TestMediaType_Quality(t *testing.T) {
+	accept := acceptHeader("application/vnd.acme.order+json;q=0.5, application/vnd.acme.order+xml")
+	httpWriter := dispatchRequest(newMediaTypeContainer(), "GET", "/orders", accept, "")
+	if "application/vnd.acme.order+xml" != httpWriter.Header().Get(HEADER_ContentType) {
+		t.Error("the preferred media type expected, got", httpWriter.Header().Get(HEADER_ContentType))
+	}
+}
+
+// go test -v -test.run TestMediaType_NotAcceptable ...restful
+func TestMediaType_NotAcceptable(t *testing.T) {
+	wc := newMediaTypeContainer()
+	for _, each := range []string{"text/html", "application/vnd.acme.invoice+json", "application/json"} {
+		if httpWriter := dispatchRequest(wc, "GET", "/orders", acceptHeader(each), ""); 406 != httpWriter.Code {
+			t.Errorf("%s: 406 expected, got %d", each, httpWriter.Code)
+		}
+	}
+}
+
+// go test -v -test.run TestMediaType_VersionParameter ...restful
+func TestMediaType_VersionParameter(t *testing.T) {
+	wc := newMediaTypeContainer()
+	httpWriter := dispatchRequest(wc, "GET", "/v2/orders", acceptHeader(`application/json; version="2"`), "")
+	if 200 != httpWriter.Code || !strings.Contains(httpWriter.Body.String(), `"id": "2"`) {
+		t.Errorf("version 2 expected, got %d: %s", httpWriter.Code, httpWriter.Body.String())
+	}
+	if "application/json;version=2" != httpWriter.Header().Get(HEADER_ContentType) {
+		t.Error("the produced parameter expected in the Content-Type, got", httpWriter.Header().Get(HEADER_ContentType))
+	}
+}
+
+// go test -v -test.run TestMediaType_VersionParameterOmitted ...restful
+func TestMediaType_VersionParameterOmitted(t *testing.T) {
+	httpWriter := dispatchRequest(newMediaTypeContainer(), "GET", "/v2/orders", acceptHeader(MIME_JSON), "")
+	if "application/json;version=2" != httpWriter.Header().Get(HEADER_ContentType) {
+		t.Error("a parameter that is not requested does not conflict, got", httpWriter.Header().Get(HEADER_ContentType))
+	}
+}
+
+// go test -v -test.run TestMediaType_VersionMismatch ...restful
+func TestMediaType_VersionMismatch(t *testing.T) {
+	if httpWriter := dispatchRequest(newMediaTypeContainer(), "GET", "/v2/orders", acceptHeader("application/json;version=1"), ""); 406 != httpWriter.Code {
+		t.Error("406 expected, got", httpWriter.Code)
+	}
+}
+
+// go test -v -test.run TestMediaType_ReadSuffixJSON ...restful
+func TestMediaType_ReadSuffixJSON(t *testing.T) {
+	header := http.Header{HEADER_ContentType: {"application/vnd.acme.order+json; charset=UTF-8"}}
+	httpWriter := dispatchRequest(newMediaTypeContainer(), "POST", "/orders", header, `{"item":"pen"}`)
+	if 200 != httpWriter.Code || "pen" != httpWriter.Body.String() {
+		t.Errorf("the JSON reader expected, got %d: %s", httpWriter.Code, httpWriter.Body.String())
+	}
+}
+
+// go test -v -test.run TestMediaType_UnsupportedMediaType ...restful
+func TestMediaType_UnsupportedMediaType(t *testing.T) {
+	header := http.Header{HEADER_ContentType: {MIME_JSON}}
+	if httpWriter := dispatchRequest(newMediaTypeContainer(), "POST", "/orders", header, `{"item":"pen"}`); 415 != httpWriter.Code {
+		t.Error("the general media type does not match the vendor media type, got", httpWriter.Code)
+	}
+}
+
+// go test -v -test.run TestMediaType_RegisteredAccessorFirst ...restful
+func TestMediaType_RegisteredAccessorFirst(t *testing.T) {
+	const vendor = "application/vnd.acme.report+json"
+	custom := NewEntityAccessorJSON(vendor)
+	RegisterEntityAccessor(vendor, custom)
+	defer func() {
+		entityAccessRegistry.protection.Lock()
+		delete(entityAccessRegistry.accessors, vendor)
+		entityAccessRegistry.protection.Unlock()
+	}()
+	if accessor, ok := entityAccessRegistry.accessorAt(vendor + ";charset=utf-8"); !ok || accessor != custom {
+		t.Error("the registered accessor must win from the suffix, got", accessor)
+	}
+	if accessor, ok := entityAccessRegistry.accessorAt("application/vnd.acme.order+json"); !ok || accessor != entityAccessRegistry.accessors[MIME_JSON] {
+		t.Error("the JSON accessor expected for the suffix, got", accessor)
+	}
+	if _, ok := entityAccessRegistry.accessorAt("application/vnd.acme.order+yaml"); ok {
+		t.Error("no accessor expected for an unknown suffix")
+	}
+}
+
+// go test -v -test.run TestParseMediaType ...restful
+func TestParseMediaType(t *testing.T) {
+	parsed := parseMediaType(` Application/Vnd.Acme.Order+JSON ; Version="2"; q=0.5`)
+	if got, want := parsed.String(), "application/vnd.acme.order+json"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := parsed.suffix, "json"; got != want {
+		t.Errorf("got suffix %q want %q", got, want)
+	}
+	if got, want := len(parsed.parameters), 1; got != want || parsed.parameters["version"] != "2" {
+		t.Errorf("only the unquoted version expected, got %v", parsed.parameters)
+	}
+	if got, want := parseMediaType("application").String(), "application/*"; got != want {
+		t.Errorf("a missing subtype is a wildcard, got %v want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestMediaTypeMatches ...restful
+func TestMediaTypeMatches(t *testing.T) {
+	tests := []struct {
+		left, right string
+		matches     bool
+	}{
+		{"application/json", "application/json", true},
+		{"Application/JSON", "application/json", true},
+		{"application/*", "application/vnd.acme+json", true},
+		{"*/*", "text/plain", true},
+		{"*", "text/plain", true},
+		{"text/*", "application/json", false},
+		{"application/json;charset=utf-8", "application/json;charset=UTF-8", true},
+		{"application/json;charset=utf-8", "application/json;charset=latin1", false},
+		{"application/json;version=2;q=0.8", "application/json", true},
+		{"application/json;version=2", "application/json;version=3", false},
+		{"application/vnd.acme+json", "application/json", false},
+	}
+	for _, each := range tests {
+		left, right := parseMediaType(each.left), parseMediaType(each.right)
+		if got, want := left.matches(right), each.matches; got != want {
+			t.Errorf("%s matches %s: got %v want %v", each.left, each.right, got, want)
+		}
+		if got, want := right.matches(left), each.matches; got != want {
+			t.Errorf("%s matches %s: got %v want %v", each.right, each.left, got, want)
+		}
+	}
+}
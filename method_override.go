@@ -0,0 +1,91 @@
+package restful
+
+// Copyright 2024 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"net/http"
+	"strings"
+)
+
+// MethodOverride is used to create a pre-routing Container Filter that changes the method of a request
+// for clients that can only send GET and POST requests. The overriding method is taken from a request header
+// or a field of an application/x-www-form-urlencoded body. The selected Route sees the overridden method.
+//
+//	container.PreRoutingFilter(restful.MethodOverride{}.Filter)
+type MethodOverride struct {
+	// Headers is a list of Header names with the overriding method, in order of precedence.
+	// If empty then X-HTTP-Method-Override and X-HTTP-Method are used.
+	Headers []string
+
+	// FormField is the name of the form field with the overriding method. If empty then "_method" is used.
+	// Only used for POST requests with the Content-Type application/x-www-form-urlencoded.
+	FormField string
+
+	// DisableFormField controls whether the FormField is inspected at all.
+	DisableFormField bool
+
+	// Allowed maps a request method (e.g. POST) to the methods it can be overridden with (e.g. PUT, PATCH, DELETE).
+	// If nil then only POST can be overridden with PUT, PATCH or DELETE. Other overrides are ignored.
+	Allowed map[string][]string
+}
+
+// Filter is a filter function that overrides the method of the request, if requested and allowed.
+// It must be installed as a pre-routing filter to affect the selection of the Route.
+func (m MethodOverride) Filter(req *Request, resp *Response, chain *FilterChain) {
+	if override := m.requestedMethod(req.Request); len(override) > 0 && override != req.Request.Method {
+		if m.isAllowed(req.Request.Method, override) {
+			if trace {
+				traceLogger.Printf("overriding HTTP method %s with %s\n", req.Request.Method, override)
+			}
+			req.Request.Method = override
+		} else if trace {
+			traceLogger.Printf("HTTP method %s is not allowed to be overridden with %s\n", req.Request.Method, override)
+		}
+	}
+	chain.ProcessFilter(req, resp)
+}
+
+// requestedMethod returns the (uppercase) overriding method or an empty string.
+func (m MethodOverride) requestedMethod(httpRequest *http.Request) string {
+	headers := m.Headers
+	if len(headers) == 0 {
+		headers = []string{HEADER_XHTTPMethodOverride, HEADER_XHTTPMethod}
+	}
+	for _, each := range headers {
+		if method := strings.TrimSpace(httpRequest.Header.Get(each)); len(method) > 0 {
+			return strings.ToUpper(method)
+		}
+	}
+	if m.DisableFormField || httpRequest.Method != http.MethodPost {
+		return ""
+	}
+	mediaType := strings.TrimSpace(strings.Split(httpRequest.Header.Get(HEADER_ContentType), ";")[0])
+	if !strings.EqualFold(mediaType, "application/x-www-form-urlencoded") {
+		return ""
+	}
+	field := m.FormField
+	if len(field) == 0 {
+		field = "_method"
+	}
+	// the parsed form remains available through Request.Request.PostForm
+	if err := httpRequest.ParseForm(); err != nil {
+		return ""
+	}
+	return strings.ToUpper(strings.TrimSpace(httpRequest.PostForm.Get(field)))
+}
+
+// isAllowed returns whether the method can be overridden.
+func (m MethodOverride) isAllowed(method, override string) bool {
+	allowed := m.Allowed
+	if allowed == nil {
+		allowed = map[string][]string{http.MethodPost: {http.MethodPut, http.MethodPatch, http.MethodDelete}}
+	}
+	for _, each := range allowed[method] {
+		if strings.EqualFold(each, override) {
+			return true
+		}
+	}
+	return false
+}
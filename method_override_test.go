@@ -0,0 +1,149 @@
+package restful
+
+import (
+	"io"
+	"net/http"
+	"testing"
+)
+
+var formContent = http.Header{"Content-Type": {"application/x-www-form-urlencoded"}}
+
+func newMethodOverrideContainer(override MethodOverride) *Container {
+	wc := NewContainer()
+	wc.PreRoutingFilter(override.Filter)
+	ws := new(WebService).Path("/")
+	echo := func(req *Request, resp *Response) {
+		io.WriteString(resp, req.Request.Method+" "+req.Request.PostForm.Get("name"))
+	}
+	ws.Route(ws.GET("/users/{id}").To(echo).Operation("getUser"))
+	ws.Route(ws.POST("/users/{id}").To(echo).Operation("postUser"))
+	ws.Route(ws.PUT("/users/{id}").To(echo).Operation("putUser"))
+	ws.Route(ws.DELETE("/users/{id}").To(echo).Operation("deleteUser"))
+	wc.Add(ws)
+	return wc
+}
+
+// go test -v -test.run TestMethodOverride_NotRequested ...restful
+func TestMethodOverride_NotRequested(t *testing.T) {
+	httpWriter := dispatchRequest(newMethodOverrideContainer(MethodOverride{}), "POST", "/users/42", nil, "")
+	if "POST " != httpWriter.Body.String() {
+		t.Errorf("POST expected, got %q", httpWriter.Body.String())
+	}
+}
+
+// go test -v -test.run TestMethodOverride_Headers ...restful
+func TestMethodOverride_Headers(t *testing.T) {
+	wc := newMethodOverrideContainer(MethodOverride{})
+	httpWriter := dispatchRequest(wc, "POST", "/users/42", http.Header{"X-Http-Method-Override": {" delete "}}, "")
+	if "DELETE " != httpWriter.Body.String() {
+		t.Errorf("the method must be trimmed and uppercased, got %q", httpWriter.Body.String())
+	}
+	httpWriter = dispatchRequest(wc, "POST", "/users/42", http.Header{"X-Http-Method": {"PUT"}}, "")
+	if "PUT " != httpWriter.Body.String() {
+		t.Errorf("PUT of the second header expected, got %q", httpWriter.Body.String())
+	}
+	header := http.Header{"X-Http-Method-Override": {"DELETE"}, "X-Http-Method": {"PUT"}}
+	if httpWriter := dispatchRequest(wc, "POST", "/users/42", header, ""); "DELETE " != httpWriter.Body.String() {
+		t.Errorf("the first header must take precedence, got %q", httpWriter.Body.String())
+	}
+}
+
+// go test -v -test.run TestMethodOverride_CustomHeaders ...restful
+func TestMethodOverride_CustomHeaders(t *testing.T) {
+	wc := newMethodOverrideContainer(MethodOverride{Headers: []string{"X-Verb"}})
+	header := http.Header{"X-Http-Method-Override": {"DELETE"}, "X-Verb": {"PUT"}}
+	if httpWriter := dispatchRequest(wc, "POST", "/users/42", header, ""); "PUT " != httpWriter.Body.String() {
+		t.Errorf("only the custom header must be used, got %q", httpWriter.Body.String())
+	}
+}
+
+// go test -v -test.run TestMethodOverride_FormField ...restful
+func TestMethodOverride_FormField(t *testing.T) {
+	httpWriter := dispatchRequest(newMethodOverrideContainer(MethodOverride{}), "POST", "/users/42", formContent, "_method=put&name=john")
+	if "PUT john" != httpWriter.Body.String() {
+		t.Errorf("PUT with the parsed form expected, got %q", httpWriter.Body.String())
+	}
+}
+
+// go test -v -test.run TestMethodOverride_CustomFormField ...restful
+func TestMethodOverride_CustomFormField(t *testing.T) {
+	wc := newMethodOverrideContainer(MethodOverride{FormField: "verb"})
+	header := http.Header{"Content-Type": {"application/x-www-form-urlencoded; charset=utf-8"}}
+	if httpWriter := dispatchRequest(wc, "POST", "/users/42", header, "_method=PUT&verb=DELETE"); "DELETE " != httpWriter.Body.String() {
+		t.Errorf("DELETE of the custom field expected, got %q", httpWriter.Body.String())
+	}
+}
+
+// go test -v -test.run TestMethodOverride_FormFieldDisabled ...restful
+func TestMethodOverride_FormFieldDisabled(t *testing.T) {
+	wc := newMethodOverrideContainer(MethodOverride{DisableFormField: true})
+	if httpWriter := dispatchRequest(wc, "POST", "/users/42", formContent, "_method=PUT&name=john"); "POST " != httpWriter.Body.String() {
+		t.Errorf("the body must not be read, got %q", httpWriter.Body.String())
+	}
+}
+
+// go test -v -test.run TestMethodOverride_FormFieldOnlyForForms ...restful
+func TestMethodOverride_FormFieldOnlyForForms(t *testing.T) {
+	wc := newMethodOverrideContainer(MethodOverride{})
+	if httpWriter := dispatchRequest(wc, "POST", "/users/42", http.Header{"Content-Type": {"text/plain"}}, "_method=PUT"); "POST " != httpWriter.Body.String() {
+		t.Errorf("a text body must not be parsed, got %q", httpWriter.Body.String())
+	}
+	overrideAll := MethodOverride{Allowed: map[string][]string{"PUT": {"DELETE"}}}
+	if httpWriter := dispatchRequest(newMethodOverrideContainer(overrideAll), "PUT", "/users/42", formContent, "_method=DELETE"); "PUT " != httpWriter.Body.String() {
+		t.Errorf("the form field is only used for POST, got %q", httpWriter.Body.String())
+	}
+}
+
+// go test -v -test.run TestMethodOverride_HeaderBeforeFormField ...restful
+func TestMethodOverride_HeaderBeforeFormField(t *testing.T) {
+	header := http.Header{"Content-Type": formContent["Content-Type"], "X-Http-Method-Override": {"DELETE"}}
+	httpWriter := dispatchRequest(newMethodOverrideContainer(MethodOverride{}), "POST", "/users/42", header, "_method=PUT")
+	if "DELETE " != httpWriter.Body.String() {
+		t.Errorf("the header must take precedence, got %q", httpWriter.Body.String())
+	}
+}
+
+// go test -v -test.run TestMethodOverride_NotAllowed ...restful
+func TestMethodOverride_NotAllowed(t *testing.T) {
+	wc := newMethodOverrideContainer(MethodOverride{})
+	if httpWriter := dispatchRequest(wc, "POST", "/users/42", http.Header{"X-Http-Method-Override": {"GET"}}, ""); "POST " != httpWriter.Body.String() {
+		t.Errorf("POST cannot become GET by default, got %q", httpWriter.Body.String())
+	}
+	if httpWriter := dispatchRequest(wc, "GET", "/users/42", http.Header{"X-Http-Method-Override": {"DELETE"}}, ""); "GET " != httpWriter.Body.String() {
+		t.Errorf("only POST can be overridden by default, got %q", httpWriter.Body.String())
+	}
+	none := newMethodOverrideContainer(MethodOverride{Allowed: map[string][]string{}})
+	if httpWriter := dispatchRequest(none, "POST", "/users/42", http.Header{"X-Http-Method-Override": {"DELETE"}}, ""); "POST " != httpWriter.Body.String() {
+		t.Errorf("an empty Allowed allows nothing, got %q", httpWriter.Body.String())
+	}
+}
+
+// go test -v -test.run TestMethodOverride_CustomAllowed ...restful
+func TestMethodOverride_CustomAllowed(t *testing.T) {
+	wc := newMethodOverrideContainer(MethodOverride{Allowed: map[string][]string{"GET": {"delete"}}})
+	if httpWriter := dispatchRequest(wc, "GET", "/users/42", http.Header{"X-Http-Method-Override": {"DELETE"}}, ""); "DELETE " != httpWriter.Body.String() {
+		t.Errorf("DELETE expected, got %q", httpWriter.Body.String())
+	}
+}
+
+// go test -v -test.run TestMethodOverride_MethodWithoutRoute ...restful
+func TestMethodOverride_MethodWithoutRoute(t *testing.T) {
+	httpWriter := dispatchRequest(newMethodOverrideContainer(MethodOverride{}), "POST", "/users/42", http.Header{"X-Http-Method-Override": {"PATCH"}}, "")
+	if 405 != httpWriter.Code {
+		t.Error("405 expected for the overridden method, got", httpWriter.Code)
+	}
+}
+
+// go test -v -test.run TestMethodOverride_ContainerFilterIsTooLate ...restful
+func TestMethodOverride_ContainerFilterIsTooLate(t *testing.T) {
+	wc := NewContainer()
+	wc.Filter(MethodOverride{}.Filter)
+	ws := new(WebService).Path("/users")
+	ws.Route(ws.POST("/{id}").To(dummy).Operation("postUser"))
+	ws.Route(ws.DELETE("/{id}").To(return204).Operation("deleteUser"))
+	wc.Add(ws)
+	httpWriter := dispatchRequest(wc, "POST", "/users/42", http.Header{"X-Http-Method-Override": {"DELETE"}}, "")
+	if 200 != httpWriter.Code {
+		t.Error("the POST Route is already selected when a Container filter runs, got", httpWriter.Code)
+	}
+}
@@ -32,17 +32,24 @@ func sortedMimes(accept string) (sorted []mime) {
 		if len(typeAndQuality) == 1 {
 			sorted = insertMime(sorted, mime{typeAndQuality[0], 1.0})
 		} else {
-			// take factor
-			qAndWeight := strings.Split(typeAndQuality[1], "=")
-			if len(qAndWeight) == 2 && strings.Trim(qAndWeight[0], " ") == qFactorWeightingKey {
-				f, err := strconv.ParseFloat(qAndWeight[1], 64)
-				if err != nil {
-					traceLogger.Printf("unable to parse quality in %s, %v", each, err)
+			// take factor ; keep the other parameters, e.g. version=2
+			media, quality := typeAndQuality[0], 1.0
+			for _, parameter := range typeAndQuality[1:] {
+				qAndWeight := strings.Split(parameter, "=")
+				if len(qAndWeight) == 2 && strings.Trim(qAndWeight[0], " ") == qFactorWeightingKey {
+					f, err := strconv.ParseFloat(qAndWeight[1], 64)
+					if err != nil {
+						traceLogger.Printf("unable to parse quality in %s, %v", each, err)
+						quality = -1
+					} else {
+						quality = f
+					}
 				} else {
-					sorted = insertMime(sorted, mime{typeAndQuality[0], f})
+					media += ";" + strings.Trim(parameter, " ")
 				}
-			} else {
-				sorted = insertMime(sorted, mime{typeAndQuality[0], 1.0})
+			}
+			if quality >= 0 {
+				sorted = insertMime(sorted, mime{media, quality})
 			}
 		}
 	}
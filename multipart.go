@@ -0,0 +1,312 @@
+package restful
+
+// Copyright 2024 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// MultipartLimits restricts the multipart/form-data body of requests for a Route.
+// The limits are enforced by the Request methods MultipartReader, FormFile, ReadEntity and ReadParameters.
+// Exceeding a size or count limit results in a ServiceError with status 413 (Request Entity Too Large),
+// a file with a content type that is not allowed results in a ServiceError with status 415 (Unsupported Media Type).
+type MultipartLimits struct {
+	// MaxMemory is the number of bytes of the parsed form that are kept in memory, the remainder is stored in temporary files.
+	// If zero then 32 MB is used.
+	MaxMemory int64
+	// MaxFileSize is the maximum number of bytes of each file. If zero then there is no limit.
+	MaxFileSize int64
+	// MaxFiles is the maximum number of files. If zero then there is no limit.
+	MaxFiles int
+	// AllowedContentTypes are the media types of files that are accepted, e.g. image/png or image/*.
+	// If empty then all content types are accepted.
+	AllowedContentTypes []string
+}
+
+// MultipartLimits sets the limits for a multipart/form-data body of requests for this route.
+func (b *RouteBuilder) MultipartLimits(limits MultipartLimits) *RouteBuilder {
+	b.multipartLimits = &limits
+	return b
+}
+
+// multipartLimits returns the limits of the selected Route or the default limits.
+func (r *Request) multipartLimits() MultipartLimits {
+	limits := MultipartLimits{}
+	if r.selectedRoute != nil && r.selectedRoute.multipartLimits != nil {
+		limits = *r.selectedRoute.multipartLimits
+	}
+	if limits.MaxMemory <= 0 {
+		limits.MaxMemory = defaultMaxMemory
+	}
+	return limits
+}
+
+// allowsContentType returns whether the media type of a file is allowed.
+func (l MultipartLimits) allowsContentType(contentType string) bool {
+	if len(l.AllowedContentTypes) == 0 {
+		return true
+	}
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	for _, each := range l.AllowedContentTypes {
+		allowed := strings.ToLower(each)
+		if allowed == mediaType || allowed == "*/*" {
+			return true
+		}
+		if strings.HasSuffix(allowed, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(allowed, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// MultipartReader returns a reader to stream the parts of a multipart/form-data body without storing them.
+// Use this instead of FormFile for large uploads. The MultipartLimits of the Route are enforced while reading.
+func (r *Request) MultipartReader() (*MultipartReader, error) {
+	reader, err := r.Request.MultipartReader()
+	if err != nil {
+		return nil, NewError(http.StatusBadRequest, "400: Invalid multipart body: "+err.Error())
+	}
+	return &MultipartReader{reader: reader, limits: r.multipartLimits()}, nil
+}
+
+// MultipartReader is an iterator over the parts of a multipart/form-data body.
+type MultipartReader struct {
+	reader *multipart.Reader
+	limits MultipartLimits
+	files  int
+}
+
+// NextPart returns the next part or io.EOF if there are no more parts.
+// Returns a ServiceError if the part is a file that exceeds the MaxFiles or has a content type that is not allowed.
+func (m *MultipartReader) NextPart() (*MultipartPart, error) {
+	part, err := m.reader.NextPart()
+	if err != nil {
+		if err == io.EOF {
+			return nil, err
+		}
+		return nil, NewError(http.StatusBadRequest, "400: Invalid multipart body: "+err.Error())
+	}
+	if len(part.FileName()) > 0 {
+		m.files++
+		if m.limits.MaxFiles > 0 && m.files > m.limits.MaxFiles {
+			return nil, newTooManyFilesError(m.limits.MaxFiles)
+		}
+		if !m.limits.allowsContentType(part.Header.Get(HEADER_ContentType)) {
+			return nil, newFileContentTypeError(part.FormName(), part.Header.Get(HEADER_ContentType))
+		}
+	}
+	return &MultipartPart{Part: part, maxSize: m.limits.MaxFileSize}, nil
+}
+
+// MultipartPart is a part of a multipart/form-data body. Reading a file part fails
+// with a ServiceError with status 413 (Request Entity Too Large) if it exceeds the MaxFileSize.
+type MultipartPart struct {
+	*multipart.Part
+	maxSize int64
+	read    int64
+}
+
+// Read is part of io.Reader
+func (p *MultipartPart) Read(b []byte) (int, error) {
+	if p.maxSize <= 0 || len(p.FileName()) == 0 {
+		return p.Part.Read(b)
+	}
+	if int64(len(b)) > p.maxSize-p.read+1 {
+		b = b[:p.maxSize-p.read+1]
+	}
+	n, err := p.Part.Read(b)
+	p.read += int64(n)
+	if p.read > p.maxSize {
+		return n, newFileTooLargeError(p.FormName(), p.maxSize)
+	}
+	return n, err
+}
+
+// FormFile returns the first file of the multipart/form-data body with the name of the (documented) MultiPartFormParameter.
+// The form is parsed once, using the MultipartLimits of the Route. Returns a ServiceError with status 400 (Bad Request)
+// if the file is missing or the body is invalid and a ServiceError with status 413 or 415 if the limits are exceeded.
+func (r *Request) FormFile(name string) (multipart.File, *multipart.FileHeader, error) {
+	if err := r.parseMultipartForm(); err != nil {
+		return nil, nil, err
+	}
+	headers := r.Request.MultipartForm.File[name]
+	if len(headers) == 0 {
+		return nil, nil, newInvalidParametersError([]string{fmt.Sprintf("form parameter %s: missing file", name)})
+	}
+	file, err := headers[0].Open()
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, headers[0], nil
+}
+
+// parseMultipartForm parses a multipart/form-data body, if not already done, and checks the limits of the files.
+// The limits are checked while the body is read such that an upload that exceeds them is stopped instead of stored first.
+func (r *Request) parseMultipartForm() error {
+	if r.Request.MultipartForm != nil {
+		return nil
+	}
+	limits := r.multipartLimits()
+	boundary := parseMediaType(r.Request.Header.Get(HEADER_ContentType)).parameters["boundary"]
+	if len(boundary) == 0 || r.Request.Body == nil || limits.MaxFileSize <= 0 && limits.MaxFiles <= 0 && len(limits.AllowedContentTypes) == 0 {
+		return multipartFormError(r.Request.ParseMultipartForm(limits.MaxMemory))
+	}
+	body := r.Request.Body
+	pipeReader, pipeWriter := io.Pipe()
+	checked := make(chan error, 1)
+	go func() {
+		// the parser reads what the checker has read
+		err := limits.check(multipart.NewReader(io.TeeReader(body, pipeWriter), boundary))
+		if err == nil {
+			_, err = io.Copy(pipeWriter, body)
+		}
+		pipeWriter.CloseWithError(err)
+		checked <- err
+	}()
+	r.Request.Body = pipeReader
+	err := r.Request.ParseMultipartForm(limits.MaxMemory)
+	// unblock the checker if the parser stopped reading
+	pipeReader.Close()
+	r.Request.Body = body
+	if violation, ok := (<-checked).(ServiceError); ok {
+		if r.Request.MultipartForm != nil {
+			r.Request.MultipartForm.RemoveAll()
+		}
+		r.Request.MultipartForm = &multipart.Form{Value: map[string][]string{}, File: map[string][]*multipart.FileHeader{}}
+		return violation
+	}
+	return multipartFormError(err)
+}
+
+// multipartFormError returns the ServiceError for the error of parsing the form, if any.
+func multipartFormError(err error) error {
+	if err == multipart.ErrMessageTooLarge {
+		return NewError(http.StatusRequestEntityTooLarge, "413: Request Entity Too Large")
+	}
+	if err != nil {
+		return NewError(http.StatusBadRequest, "400: Invalid multipart body: "+err.Error())
+	}
+	return nil
+}
+
+// check reads the parts and returns a ServiceError for the first file that exceeds the limits.
+// Returns nil for an invalid body ; that is reported by the parser.
+func (l MultipartLimits) check(reader *multipart.Reader) error {
+	files := 0
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			return nil
+		}
+		if len(part.FileName()) == 0 {
+			continue
+		}
+		files++
+		if l.MaxFiles > 0 && files > l.MaxFiles {
+			return newTooManyFilesError(l.MaxFiles)
+		}
+		if !l.allowsContentType(part.Header.Get(HEADER_ContentType)) {
+			return newFileContentTypeError(part.FormName(), part.Header.Get(HEADER_ContentType))
+		}
+		if l.MaxFileSize > 0 {
+			size, err := io.Copy(ioutil.Discard, io.LimitReader(part, l.MaxFileSize+1))
+			if err != nil {
+				return nil
+			}
+			if size > l.MaxFileSize {
+				return newFileTooLargeError(part.FormName(), l.MaxFileSize)
+			}
+		}
+	}
+}
+
+func newFileTooLargeError(name string, maxSize int64) ServiceError {
+	return NewError(http.StatusRequestEntityTooLarge, fmt.Sprintf("413: Request Entity Too Large: file %s exceeds %d bytes", name, maxSize))
+}
+
+func newTooManyFilesError(maxFiles int) ServiceError {
+	return NewError(http.StatusRequestEntityTooLarge, fmt.Sprintf("413: Request Entity Too Large: more than %d files", maxFiles))
+}
+
+func newFileContentTypeError(name, contentType string) ServiceError {
+	return NewError(http.StatusUnsupportedMediaType, fmt.Sprintf("415: Unsupported Media Type: file %s has content type %q", name, contentType))
+}
+
+// entityMultipartAccess is a EntityReaderWriter for reading multipart/form-data into a struct
+type entityMultipartAccess struct{}
+
+var (
+	fileHeaderType  = reflect.TypeOf(&multipart.FileHeader{})
+	fileHeadersType = reflect.TypeOf([]*multipart.FileHeader{})
+)
+
+// Read decodes the values and files of the form into the fields of the struct that have a form tag.
+// Fields of type *multipart.FileHeader or []*multipart.FileHeader are set to the file(s) with that name,
+// other fields are converted as in Request.ReadParameters.
+func (e entityMultipartAccess) Read(req *Request, v interface{}) error {
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Ptr || target.IsNil() || target.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("reading multipart/form-data requires a non-nil pointer to a struct, got %T", v)
+	}
+	if err := req.parseMultipartForm(); err != nil {
+		return err
+	}
+	form := req.Request.MultipartForm
+	problems := []string{}
+	structType := target.Elem().Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		name, ok := field.Tag.Lookup("form")
+		if !ok || name == "-" || len(field.PkgPath) > 0 {
+			continue
+		}
+		value := target.Elem().Field(i)
+		switch field.Type {
+		case fileHeaderType:
+			if headers := form.File[name]; len(headers) > 0 {
+				value.Set(reflect.ValueOf(headers[0]))
+			} else if field.Tag.Get("required") == "true" {
+				problems = append(problems, fmt.Sprintf("form parameter %s: missing file", name))
+			}
+			continue
+		case fileHeadersType:
+			value.Set(reflect.ValueOf(form.File[name]))
+			continue
+		}
+		if !isBindableType(field.Type) {
+			return fmt.Errorf("field %s of type %s cannot be bound to form parameter %s", field.Name, field.Type, name)
+		}
+		values := form.Value[name]
+		if len(values) == 0 {
+			if defaultValue := field.Tag.Get("default"); len(defaultValue) > 0 {
+				values = []string{defaultValue}
+			} else {
+				if field.Tag.Get("required") == "true" {
+					problems = append(problems, fmt.Sprintf("form parameter %s: missing value", name))
+				}
+				continue
+			}
+		}
+		if err := setFieldValue(value, values, field.Tag.Get("collectionFormat"), field.Tag.Get("layout")); err != nil {
+			problems = append(problems, fmt.Sprintf("form parameter %s: %v", name, err))
+		}
+	}
+	if len(problems) > 0 {
+		return newInvalidParametersError(problems)
+	}
+	return nil
+}
+
+// Write is not supported for multipart/form-data.
+func (e entityMultipartAccess) Write(resp *Response, status int, v interface{}) error {
+	return errors.New("writing multipart/form-data is not supported")
+}
@@ -0,0 +1,193 @@
+package restful
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+type uploadFile struct {
+	field, name, contentType, content string
+}
+
+func newMultipartRequest(values map[string]string, files ...uploadFile) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for k, v := range values {
+		writer.WriteField(k, v)
+	}
+	for _, each := range files {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", `form-data; name="`+each.field+`"; filename="`+each.name+`"`)
+		header.Set("Content-Type", each.contentType)
+		part, _ := writer.CreatePart(header)
+		io.WriteString(part, each.content)
+	}
+	writer.Close()
+	httpRequest, _ := http.NewRequest("POST", "/uploads", &body)
+	httpRequest.Header.Set("Content-Type", writer.FormDataContentType())
+	return httpRequest
+}
+
+var testMultipartLimits = MultipartLimits{MaxFileSize: 5, MaxFiles: 2, AllowedContentTypes: []string{"image/*", "text/plain"}}
+
+func serveMultipart(httpRequest *http.Request, function RouteFunction) *httptest.ResponseRecorder {
+	wc := NewContainer()
+	ws := new(WebService).Path("/").Consumes(MIME_MULTIPART_FORM)
+	ws.Route(ws.POST("/uploads").
+		Param(ws.MultiPartFormParameter("avatar", "the image").DataType("file")).
+		MultipartLimits(testMultipartLimits).
+		To(function).Operation("upload"))
+	wc.Add(ws)
+	httpWriter := httptest.NewRecorder()
+	wc.ServeHTTP(httpWriter, httpRequest)
+	return httpWriter
+}
+
+func formFileFunction(req *Request, resp *Response) {
+	file, header, err := req.FormFile("avatar")
+	if err != nil {
+		resp.WriteError(err.(ServiceError).Code, err)
+		return
+	}
+	defer file.Close()
+	data, _ := ioutil.ReadAll(file)
+	io.WriteString(resp, header.Filename+":"+string(data))
+}
+
+// go test -v -test.run TestRequest_FormFile ...restful
+func TestRequest_FormFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  []uploadFile
+		status int
+		body   string
+	}{
+		{"ok", []uploadFile{{"avatar", "a.png", "image/png", "12345"}}, 200, "a.png:12345"},
+		{"missing", nil, 400, "form parameter avatar: missing file"},
+		{"too large", []uploadFile{{"avatar", "a.png", "image/png", "123456"}}, 413, "file avatar exceeds 5 bytes"},
+		{"too many", []uploadFile{{"avatar", "a.png", "image/png", "1"}, {"other", "b.txt", "text/plain", "2"}, {"other", "c.txt", "text/plain", "3"}}, 413, "more than 2 files"},
+		{"content type", []uploadFile{{"avatar", "a.exe", "application/octet-stream", "1"}}, 415, `file avatar has content type "application/octet-stream"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpWriter := serveMultipart(newMultipartRequest(nil, tt.files...), formFileFunction)
+			if got, want := httpWriter.Code, tt.status; got != want {
+				t.Errorf("got %v want %v", got, want)
+			}
+			if !strings.Contains(httpWriter.Body.String(), tt.body) {
+				t.Errorf("expected %q in %q", tt.body, httpWriter.Body.String())
+			}
+		})
+	}
+}
+
+// go test -v -test.run TestRequest_MultipartReader ...restful
+func TestRequest_MultipartReader(t *testing.T) {
+	stream := func(req *Request, resp *Response) {
+		reader, err := req.MultipartReader()
+		if err != nil {
+			resp.WriteError(err.(ServiceError).Code, err)
+			return
+		}
+		names := []string{}
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err == nil {
+				_, err = ioutil.ReadAll(part)
+			}
+			if err != nil {
+				resp.WriteError(err.(ServiceError).Code, err)
+				return
+			}
+			names = append(names, part.FormName())
+		}
+		io.WriteString(resp, strings.Join(names, ","))
+	}
+	httpWriter := serveMultipart(newMultipartRequest(map[string]string{"title": "me"}, uploadFile{"avatar", "a.png", "image/png", "12345"}), stream)
+	if got, want := httpWriter.Body.String(), "title,avatar"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	httpWriter = serveMultipart(newMultipartRequest(nil, uploadFile{"avatar", "a.png", "image/png", "123456"}), stream)
+	if got, want := httpWriter.Code, http.StatusRequestEntityTooLarge; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	httpWriter = serveMultipart(newMultipartRequest(nil, uploadFile{"avatar", "a.gif", "video/mp4", "1"}), stream)
+	if got, want := httpWriter.Code, http.StatusUnsupportedMediaType; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+type uploadForm struct {
+	Title  string                  `form:"title" required:"true"`
+	Count  int                     `form:"count" default:"1"`
+	Avatar *multipart.FileHeader   `form:"avatar" required:"true"`
+	Others []*multipart.FileHeader `form:"other"`
+}
+
+// go test -v -test.run TestRequest_ReadEntityMultipart ...restful
+func TestRequest_ReadEntityMultipart(t *testing.T) {
+	read := func(req *Request, resp *Response) {
+		form := new(uploadForm)
+		if err := req.ReadEntity(form); err != nil {
+			resp.WriteError(err.(ServiceError).Code, err)
+			return
+		}
+		io.WriteString(resp, form.Title+":"+form.Avatar.Filename+":"+string(rune('0'+form.Count))+":"+string(rune('0'+len(form.Others))))
+	}
+	httpWriter := serveMultipart(newMultipartRequest(map[string]string{"title": "me"}, uploadFile{"avatar", "a.png", "image/png", "1"}, uploadFile{"other", "b.txt", "text/plain", "2"}), read)
+	if got, want := httpWriter.Body.String(), "me:a.png:1:1"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	httpWriter = serveMultipart(newMultipartRequest(map[string]string{"count": "x"}), read)
+	if got, want := httpWriter.Code, http.StatusBadRequest; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	for _, each := range []string{"form parameter title: missing value", "form parameter count: invalid integer", "form parameter avatar: missing file"} {
+		if !strings.Contains(httpWriter.Body.String(), each) {
+			t.Errorf("expected %q in %q", each, httpWriter.Body.String())
+		}
+	}
+}
+
+type countingReader struct {
+	reader io.Reader
+	read   int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	c.read += n
+	return n, err
+}
+
+// go test -v -test.run TestFormFileStopsLargeUpload ...restful
+func TestFormFileStopsLargeUpload(t *testing.T) {
+	large := strings.Repeat("x", 8<<20)
+	httpRequest := newMultipartRequest(nil, uploadFile{"avatar", "a.png", "image/png", large})
+	body := &countingReader{reader: httpRequest.Body}
+	httpRequest.Body = ioutil.NopCloser(body)
+	wc := NewContainer()
+	ws := new(WebService).Path("/")
+	ws.Route(ws.POST("/uploads").
+		MultipartLimits(MultipartLimits{MaxFileSize: 1024}).
+		To(formFileFunction).Operation("upload"))
+	wc.Add(ws)
+	httpWriter := httptest.NewRecorder()
+	wc.ServeHTTP(httpWriter, httpRequest)
+	if got, want := httpWriter.Code, http.StatusRequestEntityTooLarge; got != want {
+		t.Fatalf("got %d want %d (%s)", got, want, httpWriter.Body.String())
+	}
+	if body.read > 1<<20 {
+		t.Errorf("read %d bytes of the body, want the upload to be stopped", body.read)
+	}
+}
@@ -25,6 +25,15 @@ const (
 	// MultiPartFormParameterKind = indicator of Request parameter type "multipart/form-data"
 	MultiPartFormParameterKind
 
+	// HostParameterKind = indicator of Request parameter type "host" (a variable in a WebService host pattern)
+	HostParameterKind
+
+	// MatrixParameterKind = indicator of Request parameter type "matrix" (e.g. color in /cars;color=red)
+	MatrixParameterKind
+
+	// CookieParameterKind = indicator of Request parameter type "cookie"
+	CookieParameterKind
+
 	// CollectionFormatCSV comma separated values `foo,bar`
 	CollectionFormatCSV = CollectionFormat("csv")
 
@@ -48,6 +57,31 @@ func (cf CollectionFormat) String() string {
 	return string(cf)
 }
 
+const (
+	// StyleForm is the (OpenAPI) style of query parameters like `id=1&id=2` (explode) or `id=1,2`
+	StyleForm = ParameterStyle("form")
+
+	// StyleSimple is the (OpenAPI) style of path and header parameters like `1,2`
+	StyleSimple = ParameterStyle("simple")
+
+	// StyleSpaceDelimited is the (OpenAPI) style of query parameters like `id=1%202`
+	StyleSpaceDelimited = ParameterStyle("spaceDelimited")
+
+	// StylePipeDelimited is the (OpenAPI) style of query parameters like `id=1|2`
+	StylePipeDelimited = ParameterStyle("pipeDelimited")
+
+	// StyleDeepObject is the (OpenAPI) style of query parameters with nested keys like `filter[status]=open&filter[owner][id]=7`
+	// With parameter validation, its DefaultValue is a query of the nested keys, e.g. `status=open&owner[id]=7`
+	StyleDeepObject = ParameterStyle("deepObject")
+)
+
+// ParameterStyle describes how a parameter value is serialized, see https://spec.openapis.org/oas/v3.0.3#style-values
+type ParameterStyle string
+
+func (ps ParameterStyle) String() string {
+	return string(ps)
+}
+
 // Parameter is for documententing the parameter used in a Http Request
 // ParameterData kinds are Path,Query and Body
 type Parameter struct {
@@ -76,6 +110,8 @@ type ParameterData struct {
 	MinItems         *int64
 	MaxItems         *int64
 	UniqueItems      bool
+	Style            string
+	Explode          *bool
 }
 
 // Data returns the state of the Parameter
@@ -116,6 +152,21 @@ func (p *Parameter) beMultiPartForm() *Parameter {
 	return p
 }
 
+func (p *Parameter) beHost() *Parameter {
+	p.data.Kind = HostParameterKind
+	return p
+}
+
+func (p *Parameter) beMatrix() *Parameter {
+	p.data.Kind = MatrixParameterKind
+	return p
+}
+
+func (p *Parameter) beCookie() *Parameter {
+	p.data.Kind = CookieParameterKind
+	return p
+}
+
 // Required sets the required field and returns the receiver
 func (p *Parameter) Required(required bool) *Parameter {
 	p.data.Required = required
@@ -240,3 +291,15 @@ func (p *Parameter) UniqueItems(uniqueItems bool) *Parameter {
 	p.data.UniqueItems = uniqueItems
 	return p
 }
+
+// Style sets the style field (e.g. deepObject) and returns the receiver
+func (p *Parameter) Style(style ParameterStyle) *Parameter {
+	p.data.Style = style.String()
+	return p
+}
+
+// Explode sets the explode field and returns the receiver
+func (p *Parameter) Explode(explode bool) *Parameter {
+	p.data.Explode = &explode
+	return p
+}
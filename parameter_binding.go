@@ -0,0 +1,394 @@
+package restful
+
+// Copyright 2024 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"encoding"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultMaxMemory is the number of bytes of a multipart form that are kept in memory, same as net/http
+const defaultMaxMemory = 32 << 20
+
+// parameterTags are the struct tags that bind a field to a request parameter, in order of precedence.
+var parameterTags = []struct {
+	tag  string
+	kind int
+}{
+	{"path", PathParameterKind},
+	{"query", QueryParameterKind},
+	{"header", HeaderParameterKind},
+	{"cookie", CookieParameterKind},
+	{"form", FormParameterKind},
+}
+
+// boundField describes a struct field that is bound to a request parameter.
+type boundField struct {
+	index            []int
+	field            reflect.StructField
+	tag              string // path, query, header, cookie or form
+	kind             int
+	name             string
+	defaultValue     string
+	required         bool
+	description      string
+	collectionFormat string
+	layout           string // for time.Time fields
+}
+
+// ReadParameters fills the fields of the struct pointed to by v with the values of path, query, header, cookie and form parameters.
+// Fields are bound using the struct tags path, query, header, cookie or form with the name of the parameter:
+//
+//	type listParams struct {
+//		OrgID  string    `path:"orgId"`
+//		Limit  int       `query:"limit" default:"10"`
+//		Tags   []string  `query:"tags" collectionFormat:"pipes"`
+//		Since  time.Time `query:"since" layout:"2006-01-02"`
+//		Trace  *string   `header:"X-Trace-Id"`
+//		Token  string    `cookie:"session" required:"true"`
+//	}
+//
+// Supported field types are string, bool, ints, uints, floats, time.Duration, time.Time (RFC3339 unless the layout tag is set),
+// types that implement encoding.TextUnmarshaler, slices of these and pointers to these.
+// Slice values are split using the collectionFormat tag or else the CollectionFormat of the parameter documented on the selected Route (default csv).
+// If a value is missing then the default tag or else the DefaultValue of the documented parameter is used.
+// Returns a ServiceError with status 400 (Bad Request) that lists every parameter that is missing or could not be converted.
+func (r *Request) ReadParameters(v interface{}) error {
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Ptr || target.IsNil() || target.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("ReadParameters requires a non-nil pointer to a struct, got %T", v)
+	}
+	fields, err := boundFieldsOf(target.Elem().Type())
+	if err != nil {
+		return err
+	}
+	var documented []*Parameter
+	if r.selectedRoute != nil {
+		documented = r.selectedRoute.ParameterDocs
+	}
+	problems := []string{}
+	for _, each := range fields {
+		doc := documentedParameter(documented, each.kind, each.name)
+		values := r.parameterValues(each.tag, each.name)
+		if len(values) == 0 {
+			defaultValue := each.defaultValue
+			if len(defaultValue) == 0 && doc != nil {
+				defaultValue = doc.data.DefaultValue
+			}
+			if len(defaultValue) == 0 {
+				if each.required {
+					problems = append(problems, fmt.Sprintf("%s parameter %s: missing value", each.tag, each.name))
+				}
+				continue
+			}
+			values = []string{defaultValue}
+		}
+		format := each.collectionFormat
+		if len(format) == 0 && doc != nil {
+			format = doc.data.CollectionFormat
+		}
+		if err := setFieldValue(target.Elem().FieldByIndex(each.index), values, format, each.layout); err != nil {
+			problems = append(problems, fmt.Sprintf("%s parameter %s: %v", each.tag, each.name, err))
+		}
+	}
+	if len(problems) > 0 {
+		return newInvalidParametersError(problems)
+	}
+	return nil
+}
+
+// parameterValues returns the non-empty values of the request parameter of the tag kind.
+func (r *Request) parameterValues(tag, name string) []string {
+	var values []string
+	switch tag {
+	case "path":
+		if value, ok := r.pathParameters[name]; ok {
+			values = []string{value}
+		}
+	case "query":
+		values = r.Request.URL.Query()[name]
+	case "header":
+		values = r.Request.Header[http.CanonicalHeaderKey(name)]
+	case "cookie":
+		if value := r.CookieParameter(name); len(value) > 0 {
+			values = []string{value}
+		}
+	case "form":
+		if strings.HasPrefix(r.Request.Header.Get(HEADER_ContentType), MIME_MULTIPART_FORM) {
+			r.parseMultipartForm()
+		} else {
+			r.Request.ParseForm()
+		}
+		values = r.Request.PostForm[name]
+	}
+	nonEmpty := values[:0:0]
+	for _, each := range values {
+		if len(each) > 0 {
+			nonEmpty = append(nonEmpty, each)
+		}
+	}
+	return nonEmpty
+}
+
+// documentedParameter returns the Parameter with the kind and name or nil if absent.
+func documentedParameter(parameters []*Parameter, kind int, name string) *Parameter {
+	for _, each := range parameters {
+		if each.data.Kind == kind && each.data.Name == name {
+			return each
+		}
+	}
+	return nil
+}
+
+// boundFieldsOf returns the fields, including those of embedded structs, that have a parameter tag.
+func boundFieldsOf(structType reflect.Type) ([]boundField, error) {
+	fields := []boundField{}
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		bound := boundField{index: []int{i}, field: field}
+		for _, each := range parameterTags {
+			if name, ok := field.Tag.Lookup(each.tag); ok && name != "-" {
+				bound.tag, bound.kind, bound.name = each.tag, each.kind, name
+				break
+			}
+		}
+		if len(bound.tag) == 0 {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				embedded, err := boundFieldsOf(field.Type)
+				if err != nil {
+					return nil, err
+				}
+				for _, each := range embedded {
+					each.index = append([]int{i}, each.index...)
+					fields = append(fields, each)
+				}
+			}
+			continue
+		}
+		if len(field.PkgPath) > 0 {
+			return nil, fmt.Errorf("unexported field %s cannot be bound to %s parameter %s", field.Name, bound.tag, bound.name)
+		}
+		if !isBindableType(field.Type) {
+			return nil, fmt.Errorf("field %s of type %s cannot be bound to %s parameter %s", field.Name, field.Type, bound.tag, bound.name)
+		}
+		bound.defaultValue = field.Tag.Get("default")
+		bound.required = field.Tag.Get("required") == "true" || bound.tag == "path"
+		bound.description = field.Tag.Get("description")
+		bound.collectionFormat = field.Tag.Get("collectionFormat")
+		bound.layout = field.Tag.Get("layout")
+		fields = append(fields, bound)
+	}
+	return fields, nil
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	timeType            = reflect.TypeOf(time.Time{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// isBindableType returns whether setFieldValue can convert a string to a value of the type.
+func isBindableType(fieldType reflect.Type) bool {
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	if fieldType.Kind() == reflect.Slice {
+		fieldType = fieldType.Elem()
+	}
+	if reflect.PtrTo(fieldType).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch fieldType.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// setFieldValue converts the values and assigns the result to the field value.
+func setFieldValue(field reflect.Value, values []string, collectionFormat, layout string) error {
+	if field.Kind() == reflect.Ptr {
+		pointer := reflect.New(field.Type().Elem())
+		if err := setFieldValue(pointer.Elem(), values, collectionFormat, layout); err != nil {
+			return err
+		}
+		field.Set(pointer)
+		return nil
+	}
+	if field.Kind() == reflect.Slice && !reflect.PtrTo(field.Type()).Implements(textUnmarshalerType) {
+		items := splitCollectionFormat(values, collectionFormat)
+		slice := reflect.MakeSlice(field.Type(), len(items), len(items))
+		for i, each := range items {
+			if err := setSingleValue(slice.Index(i), each, layout); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+	return setSingleValue(field, values[0], layout)
+}
+
+// splitCollectionFormat returns the items of the values that are separated according to the collection format.
+// Values of the multi format (and repeated values of the other formats) are kept as separate items. The default format is csv.
+func splitCollectionFormat(values []string, collectionFormat string) []string {
+	separator := ","
+	switch CollectionFormat(collectionFormat) {
+	case CollectionFormatMulti:
+		return values
+	case CollectionFormatSSV:
+		separator = " "
+	case CollectionFormatTSV:
+		separator = "\t"
+	case CollectionFormatPipes:
+		separator = "|"
+	}
+	items := []string{}
+	for _, each := range values {
+		items = append(items, strings.Split(each, separator)...)
+	}
+	return items
+}
+
+// setSingleValue converts the text and assigns the result to the value.
+func setSingleValue(value reflect.Value, text, layout string) error {
+	if value.Type() == timeType && len(layout) > 0 {
+		parsed, err := time.Parse(layout, text)
+		if err != nil {
+			return fmt.Errorf("invalid time %q, expected layout %s", text, layout)
+		}
+		value.Set(reflect.ValueOf(parsed))
+		return nil
+	}
+	if unmarshaler, ok := value.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		if err := unmarshaler.UnmarshalText([]byte(text)); err != nil {
+			return fmt.Errorf("invalid value %q: %v", text, err)
+		}
+		return nil
+	}
+	if value.Type() == durationType {
+		parsed, err := time.ParseDuration(text)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", text)
+		}
+		value.SetInt(int64(parsed))
+		return nil
+	}
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(text)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(text)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", text)
+		}
+		value.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(text, 10, value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", text)
+		}
+		value.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(text, 10, value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer %q", text)
+		}
+		value.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(text, value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", text)
+		}
+		value.SetFloat(parsed)
+	default:
+		return fmt.Errorf("unsupported type %s", value.Type())
+	}
+	return nil
+}
+
+// ParametersFrom documents a Parameter for each field of the sample struct that is bound using the struct tags of Request.ReadParameters.
+// The description tag sets the Description of a Parameter.
+// Panics if the sample is not a struct (or a pointer to a struct) or has a field that cannot be bound.
+func (b *RouteBuilder) ParametersFrom(sample interface{}) *RouteBuilder {
+	sampleType := reflect.TypeOf(sample)
+	if sampleType != nil && sampleType.Kind() == reflect.Ptr {
+		sampleType = sampleType.Elem()
+	}
+	if sampleType == nil || sampleType.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("ParametersFrom requires a struct, got %T", sample))
+	}
+	fields, err := boundFieldsOf(sampleType)
+	if err != nil {
+		panic(err.Error())
+	}
+	for _, each := range fields {
+		var p *Parameter
+		switch each.kind {
+		case PathParameterKind:
+			p = PathParameter(each.name, each.description)
+		case QueryParameterKind:
+			p = QueryParameter(each.name, each.description)
+		case HeaderParameterKind:
+			p = HeaderParameter(each.name, each.description)
+		case CookieParameterKind:
+			p = CookieParameter(each.name, each.description)
+		case FormParameterKind:
+			p = FormParameter(each.name, each.description)
+		default:
+			continue
+		}
+		p.Required(each.required).DefaultValue(each.defaultValue)
+		fieldType := each.field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.Slice && !reflect.PtrTo(fieldType).Implements(textUnmarshalerType) {
+			fieldType = fieldType.Elem()
+			format := each.collectionFormat
+			if len(format) == 0 {
+				format = CollectionFormatCSV.String()
+			}
+			p.AllowMultiple(true).CollectionFormat(CollectionFormat(format))
+		}
+		dataType, dataFormat := parameterDataType(fieldType)
+		p.DataType(dataType).DataFormat(dataFormat)
+		b.Param(p)
+	}
+	return b
+}
+
+// parameterDataType returns the (Swagger) data type and format of a bound field type.
+func parameterDataType(fieldType reflect.Type) (string, string) {
+	switch {
+	case fieldType == timeType:
+		return "string", "date-time"
+	case fieldType == durationType:
+		return "string", ""
+	case reflect.PtrTo(fieldType).Implements(textUnmarshalerType):
+		return "string", ""
+	}
+	switch fieldType.Kind() {
+	case reflect.Bool:
+		return "boolean", ""
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return "integer", "int64"
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return "integer", "int32"
+	case reflect.Float32:
+		return "number", "float"
+	case reflect.Float64:
+		return "number", "double"
+	}
+	return "string", ""
+}
@@ -0,0 +1,181 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type pagingParams struct {
+	Limit  int `query:"limit" default:"10" description:"maximum number of items"`
+	Offset int `query:"offset"`
+}
+
+type listMembersParams struct {
+	pagingParams
+	OrgID   string        `path:"orgId"`
+	Tags    []string      `query:"tags" collectionFormat:"pipes"`
+	IDs     []int         `query:"ids"`
+	Active  *bool         `query:"active"`
+	Since   time.Time     `query:"since" layout:"2006-01-02"`
+	Timeout time.Duration `header:"X-Timeout"`
+	Trace   *string       `header:"X-Trace-Id"`
+	Session string        `cookie:"session" required:"true"`
+	Ignored string        `query:"-"`
+}
+
+func readParametersFrom(ws *WebService, httpRequest *http.Request, params interface{}) error {
+	var err error
+	ws.Path("/")
+	ws.Route(ws.GET("/orgs/{orgId}/members").To(func(req *Request, resp *Response) {
+		err = req.ReadParameters(params)
+	}).Operation("listMembers"))
+	wc := NewContainer()
+	wc.Add(ws)
+	wc.ServeHTTP(httptest.NewRecorder(), httpRequest)
+	return err
+}
+
+// go test -v -test.run TestReadParameters ...restful
+func TestReadParameters(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "/orgs/acme/members?tags=a|b&ids=1,2&ids=3&active=true&since=2024-02-01&offset=5", nil)
+	httpRequest.Header.Set("X-Timeout", "2s")
+	httpRequest.AddCookie(&http.Cookie{Name: "session", Value: "s3cr3t"})
+	params := new(listMembersParams)
+	if err := readParametersFrom(new(WebService), httpRequest, params); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := params.OrgID, "acme"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := params.Limit, 10; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := params.Offset, 5; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := params.Tags, []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := params.IDs, []int{1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if params.Active == nil || !*params.Active {
+		t.Errorf("got %v want true", params.Active)
+	}
+	if got, want := params.Since, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := params.Timeout, 2*time.Second; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if params.Trace != nil {
+		t.Errorf("got %v want nil", *params.Trace)
+	}
+	if got, want := params.Session, "s3cr3t"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestReadParameters_DocumentedDefaultAndFormat ...restful
+func TestReadParameters_DocumentedDefaultAndFormat(t *testing.T) {
+	type params struct {
+		Limit int      `query:"limit"`
+		Tags  []string `query:"tags"`
+	}
+	ws := new(WebService)
+	httpRequest, _ := http.NewRequest("GET", "/orgs/acme/members?tags=a%20b", nil)
+	p := new(params)
+	var err error
+	ws.Path("/")
+	ws.Route(ws.GET("/orgs/{orgId}/members").
+		Param(ws.QueryParameter("limit", "").DefaultValue("25")).
+		Param(ws.QueryParameter("tags", "").CollectionFormat(CollectionFormatSSV)).
+		To(func(req *Request, resp *Response) {
+			err = req.ReadParameters(p)
+		}).Operation("listMembers"))
+	wc := NewContainer()
+	wc.Add(ws)
+	wc.ServeHTTP(httptest.NewRecorder(), httpRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := p.Limit, 25; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := p.Tags, []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestReadParameters_Errors ...restful
+func TestReadParameters_Errors(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "/orgs/acme/members?limit=ten&ids=1,x&since=yesterday", nil)
+	err := readParametersFrom(new(WebService), httpRequest, new(listMembersParams))
+	ser, ok := err.(ServiceError)
+	if !ok {
+		t.Fatalf("got %v want ServiceError", err)
+	}
+	if got, want := ser.Code, http.StatusBadRequest; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	for _, each := range []string{
+		`query parameter limit: invalid integer "ten"`,
+		`query parameter ids: invalid integer "x"`,
+		`query parameter since: invalid time "yesterday"`,
+		`cookie parameter session: missing value`,
+	} {
+		if !strings.Contains(ser.Message, each) {
+			t.Errorf("expected %q in %q", each, ser.Message)
+		}
+	}
+}
+
+// go test -v -test.run TestReadParameters_InvalidTarget ...restful
+func TestReadParameters_InvalidTarget(t *testing.T) {
+	req := NewRequest(&http.Request{})
+	if err := req.ReadParameters(listMembersParams{}); err == nil {
+		t.Error("expected error for non-pointer")
+	}
+	type unsupported struct {
+		Values map[string]string `query:"values"`
+	}
+	if err := req.ReadParameters(new(unsupported)); err == nil {
+		t.Error("expected error for unsupported field type")
+	}
+}
+
+// go test -v -test.run TestRouteBuilder_ParametersFrom ...restful
+func TestRouteBuilder_ParametersFrom(t *testing.T) {
+	b := new(RouteBuilder).ParametersFrom(listMembersParams{})
+	if got, want := len(b.parameters), 10; got != want {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	limit := b.ParameterNamed("limit").Data()
+	if limit.Kind != QueryParameterKind || limit.DataType != "integer" || limit.DefaultValue != "10" || limit.Description != "maximum number of items" {
+		t.Errorf("unexpected limit parameter %#v", limit)
+	}
+	orgID := b.ParameterNamed("orgId").Data()
+	if orgID.Kind != PathParameterKind || !orgID.Required {
+		t.Errorf("unexpected orgId parameter %#v", orgID)
+	}
+	tags := b.ParameterNamed("tags").Data()
+	if !tags.AllowMultiple || tags.CollectionFormat != "pipes" || tags.DataType != "string" {
+		t.Errorf("unexpected tags parameter %#v", tags)
+	}
+	since := b.ParameterNamed("since").Data()
+	if since.DataType != "string" || since.DataFormat != "date-time" {
+		t.Errorf("unexpected since parameter %#v", since)
+	}
+	trace := b.ParameterNamed("X-Trace-Id").Data()
+	if trace.Kind != HeaderParameterKind || trace.Required {
+		t.Errorf("unexpected trace parameter %#v", trace)
+	}
+	session := b.ParameterNamed("session").Data()
+	if session.Kind != CookieParameterKind || !session.Required {
+		t.Errorf("unexpected session parameter %#v", session)
+	}
+}
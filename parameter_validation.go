@@ -0,0 +1,263 @@
+package restful
+
+// Copyright 2024 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// EnableParameterValidation (default=false) checks the path, query, header, cookie and form parameters of a request
+// against the ParameterDocs of the selected Route before its RouteFunction is called (after all filters).
+// Checked are Required, DataType (integer, number, boolean), Pattern, Minimum, Maximum, MinLength, MaxLength,
+// PossibleValues and, for a Parameter that AllowMultiple, the CollectionFormat, MinItems, MaxItems and UniqueItems.
+// The DefaultValue of a missing optional parameter is set on the request.
+// All violations are reported in a single ServiceError with status 400 (Bad Request) that is passed to the ServiceErrorHandler.
+// Use the RouteBuilder ParameterValidationEnabled to override this value.
+func (c *Container) EnableParameterValidation(enabled bool) {
+	c.parameterValidationEnabled = enabled
+}
+
+// ParameterValidationEnabled allows you to override the Containers value for validating the parameters of this route.
+func (b *RouteBuilder) ParameterValidationEnabled(enabled bool) *RouteBuilder {
+	b.parameterValidationEnabled = &enabled
+	return b
+}
+
+// parameterValidationEnabledFor returns whether the parameters of requests for the Route must be validated.
+func (c *Container) parameterValidationEnabledFor(route *Route) bool {
+	if route.parameterValidationEnabled != nil {
+		return *route.parameterValidationEnabled
+	}
+	return c.parameterValidationEnabled
+}
+
+// validatingParameters returns a RouteFunction that validates the parameters before calling the function.
+func (c *Container) validatingParameters(route *Route, function RouteFunction) RouteFunction {
+	return func(req *Request, resp *Response) {
+		if problems := req.validateParameters(route.ParameterDocs); len(problems) > 0 {
+			c.serviceErrorHandleFunc(newInvalidParametersError(problems), req, resp)
+			return
+		}
+		function(req, resp)
+	}
+}
+
+// validateParameters returns the violations of the documented parameters and sets the default values of missing ones.
+func (r *Request) validateParameters(parameters []*Parameter) []string {
+	problems := []string{}
+	for _, each := range parameters {
+		data := each.data
+		tag, ok := parameterKindTag(data.Kind)
+		if !ok {
+			continue
+		}
+		if data.Kind == QueryParameterKind && data.Style == StyleDeepObject.String() {
+			problems = append(problems, r.validateDeepObjectParameter(*data)...)
+			continue
+		}
+		values := r.parameterValues(tag, data.Name)
+		if len(values) == 0 {
+			if data.Required {
+				problems = append(problems, fmt.Sprintf("%s parameter %s: missing value", tag, data.Name))
+			} else if len(data.DefaultValue) > 0 {
+				r.setParameterValue(tag, data.Name, data.DefaultValue)
+			}
+			continue
+		}
+		items, itemProblems := collectionItems(*data, values)
+		for _, problem := range itemProblems {
+			problems = append(problems, fmt.Sprintf("%s parameter %s: %s", tag, data.Name, problem))
+		}
+		for _, item := range items {
+			if problem := validateParameterValue(*data, item); len(problem) > 0 {
+				problems = append(problems, fmt.Sprintf("%s parameter %s: %s", tag, data.Name, problem))
+			}
+		}
+	}
+	return problems
+}
+
+// validateDeepObjectParameter returns the violations of a query parameter with style deepObject, which is sent as keys in brackets.
+// Its DefaultValue is a query of the keys, e.g. status=open&owner[id]=7, that is set as filter[status]=open&filter[owner][id]=7.
+func (r *Request) validateDeepObjectParameter(data ParameterData) []string {
+	query := r.Request.URL.Query()
+	for key := range query {
+		if key == data.Name || strings.HasPrefix(key, data.Name+"[") {
+			_, problems := r.deepObjectQueryParameter(data.Name)
+			return problems
+		}
+	}
+	if data.Required {
+		return []string{fmt.Sprintf("query parameter %s: missing value", data.Name)}
+	}
+	if len(data.DefaultValue) == 0 {
+		return nil
+	}
+	defaults, err := url.ParseQuery(data.DefaultValue)
+	if err != nil {
+		return []string{fmt.Sprintf("query parameter %s: invalid default value %q: %v", data.Name, data.DefaultValue, err)}
+	}
+	for key, values := range defaults {
+		bracketKey := data.Name + "[" + key + "]"
+		if open := strings.Index(key, "["); open != -1 {
+			bracketKey = data.Name + "[" + key[:open] + "]" + key[open:]
+		}
+		query[bracketKey] = values
+	}
+	r.Request.URL.RawQuery = query.Encode()
+	return nil
+}
+
+// parameterKindTag returns the name of the parameter kind as used by the struct tags of ReadParameters.
+func parameterKindTag(kind int) (string, bool) {
+	switch kind {
+	case PathParameterKind, HostParameterKind:
+		return "path", true
+	case QueryParameterKind:
+		return "query", true
+	case HeaderParameterKind:
+		return "header", true
+	case CookieParameterKind:
+		return "cookie", true
+	case FormParameterKind, MultiPartFormParameterKind:
+		return "form", true
+	}
+	return "", false
+}
+
+// setParameterValue sets the value of a missing parameter such that the RouteFunction can access it as if it was sent.
+func (r *Request) setParameterValue(tag, name, value string) {
+	switch tag {
+	case "path":
+		r.pathParameters[name] = value
+	case "query":
+		query := r.Request.URL.Query()
+		query.Set(name, value)
+		r.Request.URL.RawQuery = query.Encode()
+	case "header":
+		r.Request.Header.Set(name, value)
+	case "cookie":
+		r.Request.AddCookie(&http.Cookie{Name: name, Value: value})
+	case "form":
+		if r.Request.PostForm == nil {
+			r.Request.PostForm = url.Values{}
+		}
+		r.Request.PostForm.Set(name, value)
+		if r.Request.Form != nil {
+			r.Request.Form.Set(name, value)
+		}
+	}
+}
+
+// collectionItems splits the values of a parameter that AllowMultiple according to its CollectionFormat
+// and returns the items and the violations of AllowMultiple, MinItems, MaxItems and UniqueItems.
+func collectionItems(data ParameterData, values []string) ([]string, []string) {
+	problems := []string{}
+	if !data.AllowMultiple {
+		if len(values) > 1 {
+			problems = append(problems, fmt.Sprintf("multiple values are not allowed, got %d", len(values)))
+		}
+		return values, problems
+	}
+	items := splitCollectionFormat(values, data.CollectionFormat)
+	if data.MinItems != nil && int64(len(items)) < *data.MinItems {
+		problems = append(problems, fmt.Sprintf("got %d items, minimum is %d", len(items), *data.MinItems))
+	}
+	if data.MaxItems != nil && int64(len(items)) > *data.MaxItems {
+		problems = append(problems, fmt.Sprintf("got %d items, maximum is %d", len(items), *data.MaxItems))
+	}
+	if data.UniqueItems {
+		seen := map[string]bool{}
+		for _, each := range items {
+			if seen[each] {
+				problems = append(problems, fmt.Sprintf("duplicate item %q", each))
+				break
+			}
+			seen[each] = true
+		}
+	}
+	return items, problems
+}
+
+// validateParameterValue returns the violation of the constraints of the parameter by a single value, if any.
+func validateParameterValue(data ParameterData, value string) string {
+	switch data.DataType {
+	case "integer":
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return fmt.Sprintf("invalid integer %q", value)
+		}
+	case "number":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Sprintf("invalid number %q", value)
+		}
+	case "boolean":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Sprintf("invalid boolean %q", value)
+		}
+	}
+	if data.Minimum != nil || data.Maximum != nil {
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Sprintf("invalid number %q", value)
+		}
+		if data.Minimum != nil && number < *data.Minimum {
+			return fmt.Sprintf("value %s is less than minimum %v", value, *data.Minimum)
+		}
+		if data.Maximum != nil && number > *data.Maximum {
+			return fmt.Sprintf("value %s is greater than maximum %v", value, *data.Maximum)
+		}
+	}
+	length := int64(utf8.RuneCountInString(value))
+	if data.MinLength != nil && length < *data.MinLength {
+		return fmt.Sprintf("length %d is less than minimum length %d", length, *data.MinLength)
+	}
+	if data.MaxLength != nil && length > *data.MaxLength {
+		return fmt.Sprintf("length %d is greater than maximum length %d", length, *data.MaxLength)
+	}
+	if len(data.Pattern) > 0 {
+		matcher, err := compiledParameterPattern(data.Pattern)
+		if err != nil {
+			return fmt.Sprintf("invalid pattern %q", data.Pattern)
+		}
+		if !matcher.MatchString(value) {
+			return fmt.Sprintf("value %q does not match pattern %s", value, data.Pattern)
+		}
+	}
+	if len(data.PossibleValues) > 0 && !containsString(data.PossibleValues, value) {
+		return fmt.Sprintf("value %q is not one of %s", value, strings.Join(data.PossibleValues, ", "))
+	}
+	return ""
+}
+
+// parameterPatterns caches the compiled Pattern of parameters
+var parameterPatterns sync.Map
+
+func compiledParameterPattern(pattern string) (*regexp.Regexp, error) {
+	if matcher, ok := parameterPatterns.Load(pattern); ok {
+		return matcher.(*regexp.Regexp), nil
+	}
+	matcher, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	parameterPatterns.Store(pattern, matcher)
+	return matcher, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, each := range values {
+		if each == value {
+			return true
+		}
+	}
+	return false
+}
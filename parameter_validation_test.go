@@ -0,0 +1,257 @@
+package restful
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+var requestIdHeader = http.Header{"X-Request-Id": {"42"}}
+
+func newParameterValidationContainer() *Container {
+	wc := NewContainer()
+	wc.EnableParameterValidation(true)
+	ws := new(WebService).Path("/")
+	ws.Route(ws.GET("/users/{name}").
+		Param(ws.PathParameter("name", "").Pattern("^[a-z]+$").MinLength(2)).
+		Param(ws.QueryParameter("limit", "").DataType("integer").Minimum(1).Maximum(100).DefaultValue("10")).
+		Param(ws.QueryParameter("sort", "").PossibleValues([]string{"asc", "desc"})).
+		Param(ws.QueryParameter("ids", "").AllowMultiple(true).CollectionFormat(CollectionFormatPipes).MaxItems(3).UniqueItems(true)).
+		Param(ws.HeaderParameter("X-Request-Id", "").Required(true)).
+		To(func(req *Request, resp *Response) {
+			io.WriteString(resp, req.PathParameter("name")+":"+req.QueryParameter("limit"))
+		}).Operation("getUser"))
+	ws.Route(ws.GET("/unchecked/{name}").
+		Param(ws.PathParameter("name", "").Pattern("^[a-z]+$")).
+		ParameterValidationEnabled(false).
+		To(func(req *Request, resp *Response) {
+			io.WriteString(resp, req.PathParameter("name"))
+		}).Operation("unchecked"))
+	wc.Add(ws)
+	return wc
+}
+
+// go test -v -test.run TestParameterValidation_DefaultValue ...restful
+func TestParameterValidation_DefaultValue(t *testing.T) {
+	wc := newParameterValidationContainer()
+	if httpWriter := dispatchRequest(wc, "GET", "/users/john", requestIdHeader, ""); "john:10" != httpWriter.Body.String() {
+		t.Errorf("default value expected, got %d: %s", httpWriter.Code, httpWriter.Body.String())
+	}
+	if httpWriter := dispatchRequest(wc, "GET", "/users/john?limit=50", requestIdHeader, ""); "john:50" != httpWriter.Body.String() {
+		t.Errorf("sent value expected, got %d: %s", httpWriter.Code, httpWriter.Body.String())
+	}
+}
+
+// go test -v -test.run TestParameterValidation_ReportsAllProblems ...restful
+func TestParameterValidation_ReportsAllProblems(t *testing.T) {
+	httpWriter := dispatchRequest(newParameterValidationContainer(), "GET", "/users/JO?limit=500&sort=up&ids=1|1|2|3", nil, "")
+	if 400 != httpWriter.Code {
+		t.Error("400 expected, got", httpWriter.Code)
+	}
+	want := "400: Invalid parameters: " + strings.Join([]string{
+		`path parameter name: value "JO" does not match pattern ^[a-z]+$`,
+		`query parameter limit: value 500 is greater than maximum 100`,
+		`query parameter sort: value "up" is not one of asc, desc`,
+		`query parameter ids: got 4 items, maximum is 3`,
+		`query parameter ids: duplicate item "1"`,
+		`header parameter X-Request-Id: missing value`,
+	}, "; ")
+	if want != httpWriter.Body.String() {
+		t.Errorf("got %q want %q", httpWriter.Body.String(), want)
+	}
+}
+
+// go test -v -test.run TestParameterValidation_MinimumAndMaximumInclusive ...restful
+func TestParameterValidation_MinimumAndMaximumInclusive(t *testing.T) {
+	wc := newParameterValidationContainer()
+	for url, code := range map[string]int{
+		"/users/john?limit=1":   200,
+		"/users/john?limit=100": 200,
+		"/users/john?limit=0":   400,
+		"/users/john?limit=101": 400,
+	} {
+		if httpWriter := dispatchRequest(wc, "GET", url, requestIdHeader, ""); code != httpWriter.Code {
+			t.Errorf("%s: %d expected, got %d", url, code, httpWriter.Code)
+		}
+	}
+}
+
+// go test -v -test.run TestParameterValidation_DataType ...restful
+func TestParameterValidation_DataType(t *testing.T) {
+	wc := NewContainer()
+	wc.EnableParameterValidation(true)
+	ws := new(WebService).Path("/items")
+	ws.Route(ws.GET("").
+		Param(ws.QueryParameter("page", "").DataType("integer")).
+		Param(ws.QueryParameter("ratio", "").DataType("number")).
+		Param(ws.QueryParameter("active", "").DataType("boolean")).
+		To(dummy).Operation("listItems"))
+	wc.Add(ws)
+	if httpWriter := dispatchRequest(wc, "GET", "/items?page=-2&ratio=0.5&active=true", nil, ""); 200 != httpWriter.Code {
+		t.Error("valid values expected, got", httpWriter.Body.String())
+	}
+	httpWriter := dispatchRequest(wc, "GET", "/items?page=1.5&ratio=half&active=yes", nil, "")
+	want := `400: Invalid parameters: query parameter page: invalid integer "1.5"; query parameter ratio: invalid number "half"; query parameter active: invalid boolean "yes"`
+	if want != httpWriter.Body.String() {
+		t.Errorf("got %q want %q", httpWriter.Body.String(), want)
+	}
+}
+
+// go test -v -test.run TestParameterValidation_LengthInCharacters ...restful
+func TestParameterValidation_LengthInCharacters(t *testing.T) {
+	wc := NewContainer()
+	wc.EnableParameterValidation(true)
+	ws := new(WebService).Path("/tags")
+	ws.Route(ws.GET("/{tag}").Param(ws.PathParameter("tag", "").MaxLength(3)).To(dummy).Operation("findTag"))
+	wc.Add(ws)
+	if httpWriter := dispatchRequest(wc, "GET", "/tags/%C3%A9t%C3%A9", nil, ""); 200 != httpWriter.Code {
+		t.Error("été has 3 characters, got", httpWriter.Body.String())
+	}
+	if httpWriter := dispatchRequest(wc, "GET", "/tags/abcd", nil, ""); 400 != httpWriter.Code {
+		t.Error("400 expected, got", httpWriter.Code)
+	}
+}
+
+// go test -v -test.run TestParameterValidation_MultipleValuesNotAllowed ...restful
+func TestParameterValidation_MultipleValuesNotAllowed(t *testing.T) {
+	httpWriter := dispatchRequest(newParameterValidationContainer(), "GET", "/users/john?sort=asc&sort=desc", requestIdHeader, "")
+	if !strings.HasSuffix(httpWriter.Body.String(), "query parameter sort: multiple values are not allowed, got 2") {
+		t.Errorf("got %d: %s", httpWriter.Code, httpWriter.Body.String())
+	}
+}
+
+// go test -v -test.run TestParameterValidation_CollectionFormat ...restful
+func TestParameterValidation_CollectionFormat(t *testing.T) {
+	wc := NewContainer()
+	wc.EnableParameterValidation(true)
+	ws := new(WebService).Path("/items")
+	ws.Route(ws.GET("").
+		Param(ws.QueryParameter("ids", "").AllowMultiple(true).CollectionFormat(CollectionFormatCSV).DataType("integer").MinItems(2)).
+		To(dummy).Operation("listItems"))
+	wc.Add(ws)
+	if httpWriter := dispatchRequest(wc, "GET", "/items?ids=1,2", nil, ""); 200 != httpWriter.Code {
+		t.Error("two items expected, got", httpWriter.Body.String())
+	}
+	httpWriter := dispatchRequest(wc, "GET", "/items?ids=x", nil, "")
+	want := `400: Invalid parameters: query parameter ids: got 1 items, minimum is 2; query parameter ids: invalid integer "x"`
+	if want != httpWriter.Body.String() {
+		t.Errorf("each item must be checked, got %q want %q", httpWriter.Body.String(), want)
+	}
+}
+
+// go test -v -test.run TestParameterValidation_Form ...restful
+func TestParameterValidation_Form(t *testing.T) {
+	wc := NewContainer()
+	wc.EnableParameterValidation(true)
+	ws := new(WebService).Path("/users").Consumes("application/x-www-form-urlencoded")
+	ws.Route(ws.POST("").
+		Param(ws.FormParameter("name", "").Required(true)).
+		Param(ws.FormParameter("role", "").DefaultValue("member")).
+		To(func(req *Request, resp *Response) {
+			role, _ := req.BodyParameter("role")
+			io.WriteString(resp, role)
+		}).Operation("createUser"))
+	wc.Add(ws)
+	header := http.Header{"Content-Type": {"application/x-www-form-urlencoded"}}
+	if httpWriter := dispatchRequest(wc, "POST", "/users", header, "name=john"); "member" != httpWriter.Body.String() {
+		t.Errorf("default form value expected, got %d: %s", httpWriter.Code, httpWriter.Body.String())
+	}
+	if httpWriter := dispatchRequest(wc, "POST", "/users", header, "role=admin"); 400 != httpWriter.Code {
+		t.Error("400 expected for missing form value, got", httpWriter.Code)
+	}
+}
+
+// go test -v -test.run TestParameterValidation_InvalidPattern ...restful
+func TestParameterValidation_InvalidPattern(t *testing.T) {
+	wc := NewContainer()
+	wc.EnableParameterValidation(true)
+	ws := new(WebService).Path("/users")
+	ws.Route(ws.GET("/{name}").Param(ws.PathParameter("name", "").Pattern("[a-")).To(dummy).Operation("findUser"))
+	wc.Add(ws)
+	httpWriter := dispatchRequest(wc, "GET", "/users/john", nil, "")
+	if !strings.Contains(httpWriter.Body.String(), `path parameter name: invalid pattern "[a-"`) {
+		t.Errorf("got %d: %s", httpWriter.Code, httpWriter.Body.String())
+	}
+}
+
+// go test -v -test.run TestParameterValidation_RouteOverride ...restful
+func TestParameterValidation_RouteOverride(t *testing.T) {
+	wc := newParameterValidationContainer()
+	if httpWriter := dispatchRequest(wc, "GET", "/unchecked/JOHN", nil, ""); 200 != httpWriter.Code {
+		t.Error("disabled for the Route, got", httpWriter.Code)
+	}
+	wc = NewContainer()
+	ws := new(WebService).Path("/users")
+	ws.Route(ws.GET("/{name}").Param(ws.PathParameter("name", "").Pattern("^[a-z]+$")).
+		ParameterValidationEnabled(true).To(dummy).Operation("findUser"))
+	wc.Add(ws)
+	if httpWriter := dispatchRequest(wc, "GET", "/users/JOHN", nil, ""); 400 != httpWriter.Code {
+		t.Error("enabled for the Route, got", httpWriter.Code)
+	}
+}
+
+// go test -v -test.run TestParameterValidation_AfterFilters ...restful
+func TestParameterValidation_AfterFilters(t *testing.T) {
+	wc := newParameterValidationContainer()
+	wc.Filter(func(req *Request, resp *Response, chain *FilterChain) {
+		resp.WriteErrorString(http.StatusUnauthorized, "401: Unauthorized")
+	})
+	if httpWriter := dispatchRequest(wc, "GET", "/users/J", nil, ""); 401 != httpWriter.Code {
+		t.Error("the filter must answer first, got", httpWriter.Code)
+	}
+}
+
+// go test -v -test.run TestParameterValidation_DeepObject ...restful
+func TestParameterValidation_DeepObject(t *testing.T) {
+	wc := NewContainer()
+	wc.EnableParameterValidation(true)
+	ws := new(WebService).Path("/")
+	ws.Route(ws.GET("/items").
+		Param(ws.QueryParameter("filter", "").Style(StyleDeepObject).Required(true)).
+		Param(ws.QueryParameter("page", "").Style(StyleDeepObject).DefaultValue("size=20&cursor[after]=0")).
+		To(func(req *Request, resp *Response) {
+			filter, _ := req.DeepObjectQueryParameter("filter")
+			page, _ := req.DeepObjectQueryParameter("page")
+			io.WriteString(resp, fmt.Sprint(filter, page))
+		}).Operation("listItems"))
+	wc.Add(ws)
+	if httpWriter := dispatchRequest(wc, "GET", "/items?filter[status]=open", nil, ""); "map[status:open] map[cursor:map[after:0] size:20]" != httpWriter.Body.String() {
+		t.Error("nested default value expected, got", httpWriter.Body.String())
+	}
+	if httpWriter := dispatchRequest(wc, "GET", "/items?filter[status]=open&page[size]=5", nil, ""); "map[status:open] map[size:5]" != httpWriter.Body.String() {
+		t.Error("no default value expected if any key is sent, got", httpWriter.Body.String())
+	}
+	for url, want := range map[string]string{
+		"/items":              "query parameter filter: missing value",
+		"/items?filter=open":  "query parameter filter: expected keys in brackets",
+		"/items?filter[a]]=1": "query parameter filter[a]]: malformed key",
+	} {
+		httpWriter := dispatchRequest(wc, "GET", url, nil, "")
+		if 400 != httpWriter.Code || !strings.Contains(httpWriter.Body.String(), want) {
+			t.Errorf("%s: expected 400 with %q, got %d: %s", url, want, httpWriter.Code, httpWriter.Body.String())
+		}
+	}
+}
+
+// go test -v -test.run TestParameterValidation_Cookie ...restful
+func TestParameterValidation_Cookie(t *testing.T) {
+	wc := NewContainer()
+	wc.EnableParameterValidation(true)
+	ws := new(WebService).Path("/")
+	ws.Route(ws.GET("/csrf").
+		Param(ws.CookieParameter("csrf", "").Required(true).MinLength(8)).
+		Param(ws.CookieParameter("theme", "").DefaultValue("dark")).
+		To(func(req *Request, resp *Response) {
+			io.WriteString(resp, req.CookieParameter("theme"))
+		}).Operation("csrf"))
+	wc.Add(ws)
+	if httpWriter := dispatchRequest(wc, "GET", "/csrf", http.Header{"Cookie": {"csrf=12345678"}}, ""); "dark" != httpWriter.Body.String() {
+		t.Error("default cookie value expected, got", httpWriter.Body.String())
+	}
+	httpWriter := dispatchRequest(wc, "GET", "/csrf", http.Header{"Cookie": {"csrf=1234"}}, "")
+	if want := "400: Invalid parameters: cookie parameter csrf: length 4 is less than minimum length 8"; want != httpWriter.Body.String() {
+		t.Errorf("got %q want %q", httpWriter.Body.String(), want)
+	}
+}
@@ -54,6 +54,8 @@ func templateToRegularExpression(template string) (expression string, literalCou
 				paramExpr := strings.TrimSpace(each[colon+1 : len(each)-1])
 				if paramExpr == "*" { // special case
 					buffer.WriteString("(.*)")
+				} else if constraint, ok := pathParameterConstraintNamed(paramExpr); ok {
+					buffer.WriteString(fmt.Sprintf("((?:%s))", constraint.Expression)) // named constraint, e.g. {id:int}
 				} else {
 					buffer.WriteString(fmt.Sprintf("(%s)", paramExpr)) // between colon and closing moustache
 				}
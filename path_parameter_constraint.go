@@ -0,0 +1,105 @@
+package restful
+
+// Copyright 2024 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// PathParameterConstraint describes a named type for path parameters such as {id:int} or {ref:uuid}.
+// The name of a registered constraint can be used in a path template instead of a regular expression.
+type PathParameterConstraint struct {
+	// Expression is the regular expression that a path parameter value must match completely.
+	Expression string
+	// DataType and DataFormat are used to document the Parameter, e.g. integer,int64
+	DataType   string
+	DataFormat string
+
+	matcher *regexp.Regexp // anchored compilation of Expression
+}
+
+// pathParameterConstraints is a singleton registry of named constraints
+var pathParameterConstraints = &pathParameterConstraintRegistry{
+	protection:  new(sync.RWMutex),
+	constraints: map[string]PathParameterConstraint{},
+}
+
+type pathParameterConstraintRegistry struct {
+	protection  *sync.RWMutex
+	constraints map[string]PathParameterConstraint
+}
+
+func init() {
+	RegisterPathParameterConstraint("int", PathParameterConstraint{Expression: `[-+]?[0-9]+`, DataType: "integer", DataFormat: "int64"})
+	RegisterPathParameterConstraint("uint", PathParameterConstraint{Expression: `[0-9]+`, DataType: "integer", DataFormat: "int64"})
+	RegisterPathParameterConstraint("uuid", PathParameterConstraint{Expression: `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`, DataType: "string", DataFormat: "uuid"})
+	RegisterPathParameterConstraint("date", PathParameterConstraint{Expression: `[0-9]{4}-[0-9]{2}-[0-9]{2}`, DataType: "string", DataFormat: "date"})
+	RegisterPathParameterConstraint("alpha", PathParameterConstraint{Expression: `[a-zA-Z]+`, DataType: "string"})
+}
+
+// RegisterPathParameterConstraint adds or overrides a named constraint for path parameters.
+// Register constraints before building the Routes that use them.
+// The expression must not match a slash. It panics if the expression does not compile.
+// Example:
+//
+//	restful.RegisterPathParameterConstraint("isbn", restful.PathParameterConstraint{Expression: `[0-9]{13}`, DataType: "string"})
+//	ws.Route(ws.GET("/books/{isbn:isbn}").To(findBook))
+func RegisterPathParameterConstraint(name string, constraint PathParameterConstraint) {
+	constraint.matcher = regexp.MustCompile("^(?:" + constraint.Expression + ")$")
+	pathParameterConstraints.protection.Lock()
+	defer pathParameterConstraints.protection.Unlock()
+	pathParameterConstraints.constraints[name] = constraint
+}
+
+// pathParameterConstraintNamed returns the registered constraint for the name (the part after the colon in a path parameter).
+func pathParameterConstraintNamed(name string) (PathParameterConstraint, bool) {
+	pathParameterConstraints.protection.RLock()
+	defer pathParameterConstraints.protection.RUnlock()
+	c, ok := pathParameterConstraints.constraints[strings.TrimSpace(name)]
+	return c, ok
+}
+
+// pathParameterConstraintsOf returns the constraint for each typed path parameter in the path template.
+func pathParameterConstraintsOf(path string) map[string]PathParameterConstraint {
+	typed := map[string]PathParameterConstraint{}
+	for _, each := range tokenizePath(path) {
+		each = removeCustomVerb(each)
+		if !strings.HasPrefix(each, "{") || !strings.HasSuffix(each, "}") {
+			continue
+		}
+		colon := strings.Index(each, ":")
+		if colon == -1 {
+			continue
+		}
+		if c, ok := pathParameterConstraintNamed(each[colon+1 : len(each)-1]); ok {
+			typed[strings.TrimSpace(each[1:colon])] = c
+		}
+	}
+	return typed
+}
+
+// applyPathParameterConstraints sets the DataType and DataFormat of documented path parameters
+// that are typed in the path template, unless these were changed from their defaults.
+func applyPathParameterConstraints(path string, parameters []*Parameter) {
+	typed := pathParameterConstraintsOf(path)
+	if len(typed) == 0 {
+		return
+	}
+	for _, each := range parameters {
+		if each.data.Kind != PathParameterKind {
+			continue
+		}
+		c, ok := typed[each.data.Name]
+		if !ok {
+			continue
+		}
+		if each.data.DataType == "string" && each.data.DataFormat == "" {
+			each.data.DataType = c.DataType
+			each.data.DataFormat = c.DataFormat
+		}
+	}
+}
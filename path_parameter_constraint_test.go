@@ -0,0 +1,104 @@
+package restful
+
+import (
+	"net/http"
+	"testing"
+)
+
+var constraintMatchers = []struct {
+	route   string
+	path    string
+	matches bool
+}{
+	{"/users/{id:int}", "/users/42", true},
+	{"/users/{id:int}", "/users/-42", true},
+	{"/users/{id:int}", "/users/x42", false},
+	{"/users/{id:uint}", "/users/-42", false},
+	{"/orders/{ref:uuid}", "/orders/0b4fd7d8-56a0-4f8e-9a6c-7a3b1c2d3e4f", true},
+	{"/orders/{ref:uuid}", "/orders/0b4fd7d8", false},
+	{"/days/{day:date}", "/days/2024-02-29", true},
+	{"/days/{day:date}", "/days/today", false},
+	{"/names/{name:alpha}", "/names/bob", true},
+	{"/names/{name:alpha}", "/names/bob1", false},
+	{"/users/{id:int}:activate", "/users/42:activate", true},
+	{"/users/{id:int}:activate", "/users/a:activate", false},
+}
+
+// go test -v -test.run TestPathParameterConstraint_Routers ...restful
+func TestPathParameterConstraint_Routers(t *testing.T) {
+	routers := map[string]RouteSelector{"curly": CurlyRouter{}, "jsr311": RouterJSR311{}, "radix": NewRadixRouter()}
+	for name, router := range routers {
+		for i, each := range constraintMatchers {
+			if name == "jsr311" && hasCustomVerb(each.route) {
+				continue // custom verbs are not supported by RouterJSR311
+			}
+			ws := new(WebService).Path("/")
+			ws.Route(ws.GET(each.route).To(dummy))
+			req, _ := http.NewRequest("GET", each.path, nil)
+			_, route, _ := router.SelectRoute([]*WebService{ws}, req)
+			if got, want := route != nil, each.matches; got != want {
+				t.Errorf("[%s:%d] route:%s path:%s got %v want %v", name, i, each.route, each.path, got, want)
+			}
+		}
+	}
+}
+
+// go test -v -test.run TestPathParameterConstraint_ExtractParameters ...restful
+func TestPathParameterConstraint_ExtractParameters(t *testing.T) {
+	ws := new(WebService).Path("/{tenant:alpha}")
+	ws.Route(ws.GET("/users/{id:int}:activate").To(dummy))
+	params := defaultPathProcessor{}.ExtractParameters(&ws.Routes()[0], ws, "/acme/users/42:activate")
+	if got, want := params["tenant"], "acme"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := params["id"], "42"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+
+	ws = new(WebService).Path("/{tenant:alpha}")
+	ws.Route(ws.GET("/orders/{ref:uuid}").To(dummy))
+	params = RouterJSR311{}.ExtractParameters(&ws.Routes()[0], ws, "/acme/orders/0b4fd7d8-56a0-4f8e-9a6c-7a3b1c2d3e4f")
+	if got, want := params["ref"], "0b4fd7d8-56a0-4f8e-9a6c-7a3b1c2d3e4f"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestPathParameterConstraint_Documentation ...restful
+func TestPathParameterConstraint_Documentation(t *testing.T) {
+	ws := new(WebService).Path("/{tenant:alpha}")
+	ws.Param(ws.PathParameter("tenant", "tenant name"))
+	ws.Route(ws.GET("/orders/{ref:uuid}/lines/{line:int}").To(dummy).
+		Param(ws.PathParameter("ref", "order reference")).
+		Param(ws.PathParameter("line", "line number").DataType("string").DataFormat("ordinal")))
+	tenant := ws.PathParameters()[0].Data()
+	if got, want := tenant.DataType, "string"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	ref := ws.Routes()[0].ParameterDocs[0].Data()
+	if got, want := ref.DataType+","+ref.DataFormat, "string,uuid"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	// explicit format is kept
+	line := ws.Routes()[0].ParameterDocs[1].Data()
+	if got, want := line.DataType+","+line.DataFormat, "string,ordinal"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestRegisterPathParameterConstraint ...restful
+func TestRegisterPathParameterConstraint(t *testing.T) {
+	RegisterPathParameterConstraint("isbn", PathParameterConstraint{Expression: `[0-9]{13}`, DataType: "string", DataFormat: "isbn"})
+	ws := new(WebService).Path("/")
+	ws.Route(ws.GET("/books/{isbn:isbn}").To(dummy).Param(ws.PathParameter("isbn", "")))
+	if got, want := ws.Routes()[0].ParameterDocs[0].Data().DataFormat, "isbn"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	req, _ := http.NewRequest("GET", "/books/9780134190440", nil)
+	if _, route, _ := (CurlyRouter{}).SelectRoute([]*WebService{ws}, req); route == nil {
+		t.Error("expected route")
+	}
+	req, _ = http.NewRequest("GET", "/books/978", nil)
+	if _, route, _ := (CurlyRouter{}).SelectRoute([]*WebService{ws}, req); route != nil {
+		t.Error("expected no route")
+	}
+}
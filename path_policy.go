@@ -0,0 +1,266 @@
+package restful
+
+// Copyright 2024 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// TrailingSlashPolicy determines how a trailing slash in the request path is handled.
+type TrailingSlashPolicy int
+
+const (
+	// TrailingSlashLenient matches a request path with or without a trailing slash, e.g. /users/ selects the Route for /users.
+	TrailingSlashLenient TrailingSlashPolicy = iota
+	// TrailingSlashStrict only matches a request path if its trailing slash is as declared by the Route.
+	TrailingSlashStrict
+	// TrailingSlashRedirect redirects a request to the path as declared by the Route.
+	TrailingSlashRedirect
+)
+
+// PathPolicy controls how the path of a request is normalized before selecting a Route.
+// It applies to all RouteSelectors (CurlyRouter, RouterJSR311, RadixRouter) and to the computation of allowed methods (CORS, OPTIONS).
+//
+//	container.PathPolicy(restful.PathPolicy{
+//		TrailingSlash:   restful.TrailingSlashRedirect,
+//		CleanPath:       true,
+//		CaseInsensitive: true,
+//	})
+//
+// Note that the http.ServeMux of a Container only dispatches requests to the Container if the path starts with the (case-sensitive)
+// root path of a WebService ; add a WebService with root path "/" to have all requests dispatched.
+type PathPolicy struct {
+	// TrailingSlash determines whether a trailing slash must match the declared path of the Route.
+	// A Route declares a trailing slash if its path, or the root path of its WebService if the Route path is empty, ends with a slash.
+	TrailingSlash TrailingSlashPolicy
+
+	// CleanPath removes duplicate slashes and resolves . and .. elements in the request path, e.g. /users//./42 => /users/42
+	CleanPath bool
+
+	// CaseInsensitive matches the static elements of the path of a Route ignoring case, e.g. /Users/42 selects the Route for /users/{id}
+	CaseInsensitive bool
+
+	// UseEncodedPath uses the escaped (percent-encoded) path of the request to select a Route such that
+	// an escaped slash (%2F) is part of a path parameter value instead of a separator. Path parameter values are unescaped.
+	UseEncodedPath bool
+
+	// RedirectCode is the status code for redirects if TrailingSlash is TrailingSlashRedirect.
+	// If zero then 301 (Moved Permanently) is used for GET and HEAD, 308 (Permanent Redirect) otherwise.
+	// With TrailingSlashRedirect, requests with a path that is not clean or differs in case are redirected as well.
+	RedirectCode int
+}
+
+// PathPolicy sets the policy to normalize request paths before selecting a Route.
+// Without a policy, requests are matched as is and the trailing slash handling depends on TrimRightSlashEnabled and the Router.
+func (c *Container) PathPolicy(policy PathPolicy) {
+	c.pathPolicy = &policy
+}
+
+// requestPath returns the path of the request as it is used to select a Route.
+func (p PathPolicy) requestPath(httpRequest *http.Request) string {
+	requestPath := httpRequest.URL.Path
+	if p.UseEncodedPath {
+		requestPath = httpRequest.URL.EscapedPath()
+	}
+	if p.CleanPath {
+		requestPath = cleanPath(requestPath)
+	}
+	return requestPath
+}
+
+// candidatePaths returns the paths, in order of preference, that can be used to select a Route for the requestPath.
+func (p PathPolicy) candidatePaths(webServices []*WebService, requestPath string) []string {
+	paths := []string{requestPath}
+	if p.CaseInsensitive {
+		if canonical, ok := canonicalCasePath(webServices, requestPath); ok && canonical != requestPath {
+			paths = append(paths, canonical)
+		}
+	}
+	if p.TrailingSlash == TrailingSlashStrict {
+		return paths
+	}
+	for _, each := range paths {
+		if each != "/" {
+			paths = append(paths, toggleTrailingSlash(each))
+		}
+	}
+	return paths
+}
+
+// selectRoute lets the router select a Route for each candidate path until one is found.
+// It returns the path that was used to select the Route.
+func (p PathPolicy) selectRoute(router RouteSelector, webServices []*WebService, httpRequest *http.Request) (*WebService, *Route, string, error) {
+	requestPath := p.requestPath(httpRequest)
+	var webService *WebService
+	var route *Route
+	var err error
+	var selectedPath string
+	for _, each := range p.candidatePaths(webServices, requestPath) {
+		webService, route, err = router.SelectRoute(webServices, withURLPath(httpRequest, each))
+		selectedPath = each
+		if ser, ok := err.(ServiceError); !ok || ser.Code != http.StatusNotFound {
+			break
+		}
+	}
+	if err != nil || p.TrailingSlash == TrailingSlashLenient {
+		return webService, route, selectedPath, err
+	}
+	declaredPath := selectedPath
+	if !hasTrailingWildcard(route) {
+		declaredPath = strings.TrimRight(selectedPath, "/")
+		if declaresTrailingSlash(webService, route) {
+			declaredPath += "/"
+		}
+		if len(declaredPath) == 0 {
+			declaredPath = "/"
+		}
+	}
+	if p.TrailingSlash == TrailingSlashStrict {
+		if declaredPath != selectedPath {
+			if trace {
+				traceLogger.Printf("trailing slash of URL path:%s does not match the Route path:%s\n", selectedPath, route.Path)
+			}
+			return webService, nil, selectedPath, NewError(http.StatusNotFound, "404: Page Not Found")
+		}
+		return webService, route, selectedPath, nil
+	}
+	// TrailingSlashRedirect
+	originalPath := httpRequest.URL.Path
+	if p.UseEncodedPath {
+		originalPath = httpRequest.URL.EscapedPath()
+	}
+	if declaredPath != originalPath {
+		return webService, nil, selectedPath, p.redirectError(httpRequest, declaredPath)
+	}
+	return webService, route, selectedPath, nil
+}
+
+// redirectError returns a ServiceError with a Location header for the canonical path.
+func (p PathPolicy) redirectError(httpRequest *http.Request, canonicalPath string) ServiceError {
+	code := p.RedirectCode
+	if code == 0 {
+		code = http.StatusPermanentRedirect
+		if httpRequest.Method == http.MethodGet || httpRequest.Method == http.MethodHead {
+			code = http.StatusMovedPermanently
+		}
+	}
+	location := canonicalPath
+	if !p.UseEncodedPath {
+		location = (&url.URL{Path: canonicalPath}).EscapedPath()
+	}
+	if len(httpRequest.URL.RawQuery) > 0 {
+		location += "?" + httpRequest.URL.RawQuery
+	}
+	if trace {
+		traceLogger.Printf("redirecting URL path:%s to:%s\n", httpRequest.URL.Path, location)
+	}
+	return NewErrorWithHeader(code, http.StatusText(code), http.Header{"Location": []string{location}})
+}
+
+// unescapeParameters replaces the path parameter values by their unescaped values if UseEncodedPath is set.
+func (p PathPolicy) unescapeParameters(pathParams map[string]string) {
+	if !p.UseEncodedPath {
+		return
+	}
+	for name, value := range pathParams {
+		if unescaped, err := url.PathUnescape(value); err == nil {
+			pathParams[name] = unescaped
+		}
+	}
+}
+
+// withURLPath returns a shallow copy of the request with a different URL path.
+func withURLPath(httpRequest *http.Request, urlPath string) *http.Request {
+	if httpRequest.URL.Path == urlPath {
+		return httpRequest
+	}
+	copied := new(http.Request)
+	*copied = *httpRequest
+	copiedURL := *httpRequest.URL
+	copiedURL.Path = urlPath
+	copiedURL.RawPath = ""
+	copied.URL = &copiedURL
+	return copied
+}
+
+// cleanPath returns the shortest equivalent path but keeps a trailing slash, e.g. /users//./42/ => /users/42/
+func cleanPath(requestPath string) string {
+	if len(requestPath) == 0 {
+		return "/"
+	}
+	cleaned := path.Clean("/" + requestPath)
+	if cleaned != "/" && strings.HasSuffix(requestPath, "/") {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+// toggleTrailingSlash adds a trailing slash if absent, removes it otherwise.
+func toggleTrailingSlash(requestPath string) string {
+	if strings.HasSuffix(requestPath, "/") {
+		return strings.TrimRight(requestPath, "/")
+	}
+	return requestPath + "/"
+}
+
+// declaresTrailingSlash returns whether the Route, or its WebService if the Route has no path, ends with a slash.
+func declaresTrailingSlash(webService *WebService, route *Route) bool {
+	declared := route.relativePath
+	if declared == "" || declared == "/" {
+		declared = webService.rootPath
+	}
+	return len(declared) > 1 && strings.HasSuffix(declared, "/")
+}
+
+// hasTrailingWildcard returns whether the last path element of the Route is a {name:*} parameter.
+func hasTrailingWildcard(route *Route) bool {
+	if route == nil || len(route.pathParts) == 0 {
+		return false
+	}
+	return strings.HasSuffix(route.pathParts[len(route.pathParts)-1], "*}")
+}
+
+// canonicalCasePath returns the request path with its static elements replaced by those of the first Route
+// whose static elements match ignoring case.
+func canonicalCasePath(webServices []*WebService, requestPath string) (string, bool) {
+	requestTokens := strings.Split(strings.Trim(requestPath, "/"), "/")
+	for _, ws := range webServices {
+		// Routes takes the lock of a WebService with dynamic routes
+		for _, route := range ws.Routes() {
+			routeTokens := strings.Split(strings.Trim(route.Path, "/"), "/")
+			if canonical, ok := matchTokensIgnoringCase(routeTokens, requestTokens); ok {
+				result := "/" + strings.Join(canonical, "/")
+				if result != "/" && strings.HasSuffix(requestPath, "/") {
+					result += "/"
+				}
+				return result, true
+			}
+		}
+	}
+	return "", false
+}
+
+// matchTokensIgnoringCase returns a copy of the request tokens with static tokens taken from the route tokens.
+func matchTokensIgnoringCase(routeTokens, requestTokens []string) ([]string, bool) {
+	wildcard := len(routeTokens) > 0 && strings.HasSuffix(routeTokens[len(routeTokens)-1], "*}")
+	if len(routeTokens) != len(requestTokens) && !(wildcard && len(requestTokens) >= len(routeTokens)) {
+		return nil, false
+	}
+	canonical := make([]string, len(requestTokens))
+	copy(canonical, requestTokens)
+	for i, routeToken := range routeTokens {
+		if strings.Contains(routeToken, "{") {
+			continue
+		}
+		if !strings.EqualFold(routeToken, requestTokens[i]) {
+			return nil, false
+		}
+		canonical[i] = routeToken
+	}
+	return canonical, true
+}
@@ -0,0 +1,208 @@
+package restful
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+)
+
+var pathPolicyRouters = map[string]RouteSelector{"curly": CurlyRouter{}, "jsr311": RouterJSR311{}, "radix": NewRadixRouter()}
+
+func newPathPolicyContainer(router RouteSelector, policy PathPolicy) *Container {
+	wc := NewContainer()
+	wc.Router(router)
+	wc.PathPolicy(policy)
+	ws := new(WebService).Path("/users")
+	ws.Route(ws.GET("/{id}").To(func(req *Request, resp *Response) {
+		resp.Write([]byte(req.PathParameter("id")))
+	}).Operation("findUser"))
+	ws.Route(ws.PUT("/{id}").To(dummy).Operation("updateUser"))
+	ws.Route(ws.GET("/{id}/orders/").To(dummy).Operation("listOrders"))
+	ws.Route(ws.GET("/{id}/files/{path:*}").To(func(req *Request, resp *Response) {
+		resp.Write([]byte(req.PathParameter("path")))
+	}).Operation("findFile"))
+	wc.Add(ws)
+	return wc
+}
+
+// go test -v -test.run TestPathPolicy_LenientTrailingSlashByDefault ...restful
+func TestPathPolicy_LenientTrailingSlashByDefault(t *testing.T) {
+	for name, router := range pathPolicyRouters {
+		wc := newPathPolicyContainer(router, PathPolicy{})
+		for _, url := range []string{"/users/42", "/users/42/", "/users/42/orders", "/users/42/orders/"} {
+			if httpWriter := dispatchRequest(wc, "GET", url, nil, ""); 200 != httpWriter.Code {
+				t.Errorf("[%s] %s: 200 expected, got %d", name, url, httpWriter.Code)
+			}
+		}
+		if httpWriter := dispatchRequest(wc, "GET", "/Users/42", nil, ""); 404 != httpWriter.Code {
+			t.Errorf("[%s] 404 expected, case matters by default, got %d", name, httpWriter.Code)
+		}
+	}
+}
+
+// go test -v -test.run TestPathPolicy_StrictTrailingSlash ...restful
+func TestPathPolicy_StrictTrailingSlash(t *testing.T) {
+	for name, router := range pathPolicyRouters {
+		wc := newPathPolicyContainer(router, PathPolicy{TrailingSlash: TrailingSlashStrict})
+		if httpWriter := dispatchRequest(wc, "GET", "/users/42/", nil, ""); 404 != httpWriter.Code {
+			t.Errorf("[%s] 404 expected for an undeclared trailing slash, got %d", name, httpWriter.Code)
+		}
+		if httpWriter := dispatchRequest(wc, "GET", "/users/42/orders", nil, ""); 404 != httpWriter.Code {
+			t.Errorf("[%s] 404 expected for a missing declared trailing slash, got %d", name, httpWriter.Code)
+		}
+		if httpWriter := dispatchRequest(wc, "GET", "/users/42/orders/", nil, ""); 200 != httpWriter.Code {
+			t.Errorf("[%s] 200 expected for a declared trailing slash, got %d", name, httpWriter.Code)
+		}
+	}
+}
+
+// go test -v -test.run TestPathPolicy_StrictTrailingSlashWildcard ...restful
+func TestPathPolicy_StrictTrailingSlashWildcard(t *testing.T) {
+	for name, router := range pathPolicyRouters {
+		wc := newPathPolicyContainer(router, PathPolicy{TrailingSlash: TrailingSlashStrict})
+		httpWriter := dispatchRequest(wc, "GET", "/users/42/files/a/b", nil, "")
+		if 200 != httpWriter.Code || "a/b" != httpWriter.Body.String() {
+			t.Errorf("[%s] the wildcard must match the remaining path, got %d: %s", name, httpWriter.Code, httpWriter.Body.String())
+		}
+	}
+}
+
+// go test -v -test.run TestPathPolicy_RedirectTrailingSlash ...restful
+func TestPathPolicy_RedirectTrailingSlash(t *testing.T) {
+	wc := newPathPolicyContainer(CurlyRouter{}, PathPolicy{TrailingSlash: TrailingSlashRedirect})
+	httpWriter := dispatchRequest(wc, "GET", "/users/42/?x=1", nil, "")
+	if 301 != httpWriter.Code || "/users/42?x=1" != httpWriter.Header().Get("Location") {
+		t.Errorf("301 to the declared path with the query expected, got %d to %s", httpWriter.Code, httpWriter.Header().Get("Location"))
+	}
+	httpWriter = dispatchRequest(wc, "GET", "/users/42/orders", nil, "")
+	if 301 != httpWriter.Code || "/users/42/orders/" != httpWriter.Header().Get("Location") {
+		t.Errorf("301 to the declared trailing slash expected, got %d to %s", httpWriter.Code, httpWriter.Header().Get("Location"))
+	}
+}
+
+// go test -v -test.run TestPathPolicy_RedirectKeepsMethod ...restful
+func TestPathPolicy_RedirectKeepsMethod(t *testing.T) {
+	wc := newPathPolicyContainer(CurlyRouter{}, PathPolicy{TrailingSlash: TrailingSlashRedirect})
+	httpWriter := dispatchRequest(wc, "PUT", "/users/42/", nil, "")
+	if 308 != httpWriter.Code || "/users/42" != httpWriter.Header().Get("Location") {
+		t.Errorf("308 expected such that the client repeats the PUT, got %d to %s", httpWriter.Code, httpWriter.Header().Get("Location"))
+	}
+}
+
+// go test -v -test.run TestPathPolicy_RedirectCode ...restful
+func TestPathPolicy_RedirectCode(t *testing.T) {
+	wc := newPathPolicyContainer(CurlyRouter{}, PathPolicy{TrailingSlash: TrailingSlashRedirect, RedirectCode: 302})
+	if httpWriter := dispatchRequest(wc, "GET", "/users/42/", nil, ""); 302 != httpWriter.Code {
+		t.Error("302 expected, got", httpWriter.Code)
+	}
+}
+
+// go test -v -test.run TestPathPolicy_RedirectToCleanCanonicalPath ...restful
+func TestPathPolicy_RedirectToCleanCanonicalPath(t *testing.T) {
+	wc := newPathPolicyContainer(CurlyRouter{}, PathPolicy{TrailingSlash: TrailingSlashRedirect, CleanPath: true, CaseInsensitive: true})
+	httpWriter := dispatchRequest(wc, "GET", "/USERS//42/./", nil, "")
+	if 301 != httpWriter.Code || "/users/42" != httpWriter.Header().Get("Location") {
+		t.Errorf("one redirect to the canonical path expected, got %d to %s", httpWriter.Code, httpWriter.Header().Get("Location"))
+	}
+}
+
+// go test -v -test.run TestPathPolicy_CleanPath ...restful
+func TestPathPolicy_CleanPath(t *testing.T) {
+	for name, router := range pathPolicyRouters {
+		wc := newPathPolicyContainer(router, PathPolicy{CleanPath: true})
+		httpWriter := dispatchRequest(wc, "GET", "/users//x/../42", nil, "")
+		if 200 != httpWriter.Code || "42" != httpWriter.Body.String() {
+			t.Errorf("[%s] 42 expected, got %d: %s", name, httpWriter.Code, httpWriter.Body.String())
+		}
+	}
+}
+
+// go test -v -test.run TestPathPolicy_CaseInsensitive ...restful
+func TestPathPolicy_CaseInsensitive(t *testing.T) {
+	for name, router := range pathPolicyRouters {
+		wc := newPathPolicyContainer(router, PathPolicy{CaseInsensitive: true})
+		httpWriter := dispatchRequest(wc, "GET", "/Users/AbC", nil, "")
+		if 200 != httpWriter.Code || "AbC" != httpWriter.Body.String() {
+			t.Errorf("[%s] the case of parameter values must be kept, got %d: %s", name, httpWriter.Code, httpWriter.Body.String())
+		}
+		if httpWriter := dispatchRequest(wc, "GET", "/USERS/42/ORDERS/", nil, ""); 200 != httpWriter.Code {
+			t.Errorf("[%s] 200 expected, got %d", name, httpWriter.Code)
+		}
+	}
+}
+
+// go test -v -test.run TestPathPolicy_CaseInsensitiveDynamicRoutes ...restful
+func TestPathPolicy_CaseInsensitiveDynamicRoutes(t *testing.T) {
+	wc := NewContainer()
+	// the JSR311 router reads the Routes of a WebService using its lock
+	wc.Router(RouterJSR311{})
+	wc.PathPolicy(PathPolicy{CaseInsensitive: true})
+	ws := new(WebService).Path("/users")
+	ws.SetDynamicRoutes(true)
+	ws.Route(ws.GET("/{id}").To(dummy).Operation("findUser"))
+	wc.Add(ws)
+	// run with -race to detect reading the Routes while they change
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			ws.Route(ws.DELETE("/{id}").To(dummy).Operation("removeUser"))
+			ws.RemoveRoute("/users/{id}", "DELETE")
+		}
+	}()
+	for i := 0; i < 1000; i++ {
+		if httpWriter := dispatchRequest(wc, "GET", "/USERS/42", nil, ""); 200 != httpWriter.Code {
+			t.Fatal("200 expected, got", httpWriter.Code)
+		}
+	}
+	wg.Wait()
+}
+
+// go test -v -test.run TestPathPolicy_UseEncodedPath ...restful
+func TestPathPolicy_UseEncodedPath(t *testing.T) {
+	for name, router := range pathPolicyRouters {
+		wc := newPathPolicyContainer(router, PathPolicy{})
+		if httpWriter := dispatchRequest(wc, "GET", "/users/a%2Fb", nil, ""); 404 != httpWriter.Code {
+			t.Errorf("[%s] 404 expected because the decoded path has an extra element, got %d", name, httpWriter.Code)
+		}
+		wc.PathPolicy(PathPolicy{UseEncodedPath: true})
+		httpWriter := dispatchRequest(wc, "GET", "/users/a%2Fb", nil, "")
+		if 200 != httpWriter.Code || "a/b" != httpWriter.Body.String() {
+			t.Errorf("[%s] decoded path parameter expected, got %d: %s", name, httpWriter.Code, httpWriter.Body.String())
+		}
+		httpWriter = dispatchRequest(wc, "GET", "/users/42/files/a%2Fb/c", nil, "")
+		if 200 != httpWriter.Code || "a/b/c" != httpWriter.Body.String() {
+			t.Errorf("[%s] decoded wildcard expected, got %d: %s", name, httpWriter.Code, httpWriter.Body.String())
+		}
+	}
+}
+
+// go test -v -test.run TestPathPolicy_AllowedMethods ...restful
+func TestPathPolicy_AllowedMethods(t *testing.T) {
+	wc := newPathPolicyContainer(CurlyRouter{}, PathPolicy{CaseInsensitive: true, CleanPath: true})
+	httpRequest, _ := http.NewRequest("OPTIONS", "http://example.com/USERS//42/", nil)
+	methods := wc.computeAllowedMethods(NewRequest(httpRequest))
+	if got, want := len(methods), 2; got != want {
+		t.Fatalf("got %v want %v", methods, want)
+	}
+	wc.PathPolicy(PathPolicy{TrailingSlash: TrailingSlashStrict})
+	if got, want := len(wc.computeAllowedMethods(NewRequest(httpRequest))), 0; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+func TestCleanPath(t *testing.T) {
+	for in, want := range map[string]string{
+		"":               "/",
+		"/":              "/",
+		"//users//42":    "/users/42",
+		"/users/./42/":   "/users/42/",
+		"/users/x/../42": "/users/42",
+		"/../users":      "/users",
+	} {
+		if got := cleanPath(in); got != want {
+			t.Errorf("%s: got %v want %v", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package restful
+
+// Copyright 2024 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// RadixRouter expects Routes with paths that contain zero or more parameters in curly brackets.
+// It selects the same Routes as the CurlyRouter but uses a prefix tree of path tokens,
+// precompiled for each WebService, such that selecting the candidate Routes for a request
+// does not require inspecting every Route of the WebService.
+// The tree of a WebService is rebuilt when it is added to or removed from a Container and when its Routes change.
+// A RadixRouter must be created using NewRadixRouter.
+type RadixRouter struct {
+	lock  sync.RWMutex
+	trees map[*WebService]*radixTree
+}
+
+// NewRadixRouter returns a new RadixRouter that can be set on a Container using Router(..).
+func NewRadixRouter() *RadixRouter {
+	return &RadixRouter{trees: map[*WebService]*radixTree{}}
+}
+
+// SelectRoute is part of the Router interface and returns the best match
+// for the WebService and its Route for the given Request.
+func (r *RadixRouter) SelectRoute(
+	webServices []*WebService,
+	httpRequest *http.Request) (selectedService *WebService, selected *Route, err error) {
+
+	requestTokens := tokenizePath(httpRequest.URL.Path)
+
+	webServices = selectWebServicesByHost(webServices, httpRequest.Host)
+	detectedService := CurlyRouter{}.detectWebService(requestTokens, webServices)
+	if detectedService == nil {
+		if trace {
+			traceLogger.Printf("no WebService was found to match URL path:%s\n", httpRequest.URL.Path)
+		}
+		return nil, nil, NewError(http.StatusNotFound, "404: Page Not Found")
+	}
+	candidateRoutes := r.selectRoutes(detectedService, requestTokens)
+	if len(candidateRoutes) == 0 {
+		if trace {
+			traceLogger.Printf("no Route in WebService with path %s was found to match URL path:%s\n", detectedService.rootPath, httpRequest.URL.Path)
+		}
+		return detectedService, nil, NewError(http.StatusNotFound, "404: Page Not Found")
+	}
+	selectedRoute, err := jsr311Router.detectRoute(candidateRoutes.routes(), httpRequest)
+	if selectedRoute == nil {
+		return detectedService, nil, err
+	}
+	return detectedService, selectedRoute, nil
+}
+
+// compile is part of the compilingRouteSelector interface and rebuilds the trees of all WebServices.
+func (r *RadixRouter) compile(webServices []*WebService) {
+	trees := make(map[*WebService]*radixTree, len(webServices))
+	for _, each := range webServices {
+		trees[each] = newRadixTree(each)
+	}
+	r.lock.Lock()
+	r.trees = trees
+	r.lock.Unlock()
+}
+
+// treeOf returns the tree for a WebService ; it is (re)built if missing or if its Routes have changed.
+func (r *RadixRouter) treeOf(ws *WebService) *radixTree {
+	version := atomic.LoadUint32(&ws.routesVersion)
+	r.lock.RLock()
+	tree, ok := r.trees[ws]
+	r.lock.RUnlock()
+	if ok && tree.version == version {
+		return tree
+	}
+	tree = newRadixTree(ws)
+	r.lock.Lock()
+	if r.trees == nil {
+		r.trees = map[*WebService]*radixTree{}
+	}
+	r.trees[ws] = tree
+	r.lock.Unlock()
+	return tree
+}
+
+// selectRoutes return a collection of Route from a WebService that matches the path tokens from the request.
+// The collection is sorted the same way as the CurlyRouter does.
+func (r *RadixRouter) selectRoutes(ws *WebService, requestTokens []string) sortableCurlyRoutes {
+	tree := r.treeOf(ws)
+	matches := make([]radixMatch, 0, 8)
+	tree.root.collect(requestTokens, 0, 0, 0, &matches)
+	// keep the order in which routes were added such that sorting has the same outcome as the CurlyRouter
+	sort.Slice(matches, func(i, j int) bool { return matches[i].leaf.index < matches[j].leaf.index })
+	candidates := make(sortableCurlyRoutes, 0, len(matches))
+	for _, each := range matches {
+		candidates.add(curlyRoute{each.leaf.route, each.paramCount, each.staticCount})
+	}
+	sort.Sort(candidates)
+	return candidates
+}
+
+// radixTree is the compiled prefix tree of all Routes of one WebService.
+type radixTree struct {
+	version uint32 // routesVersion of the WebService at the time of compilation
+	root    *radixNode
+}
+
+// radixNode has the Routes whose path ends at this node and the edges to nodes for the next path token.
+type radixNode struct {
+	literals map[string]*radixNode // edges for tokens that must be equal to the request token
+	edges    []*radixEdge          // edges for parameter tokens and tokens with a custom verb
+	leaves   []*radixLeaf          // routes whose path has no more tokens
+}
+
+// radixEdge is a path token that is not a plain literal, e.g. {id}, {id:[0-9]+}, {tail:*} or name:verb
+type radixEdge struct {
+	token      string         // route token as defined by the Route
+	verb       string         // custom verb (without colon) or empty
+	literal    string         // token without custom verb if it is not a parameter
+	isParam    bool           // token without custom verb starts with {
+	wildcard   bool           // matches the remainder of the request path
+	expression *regexp.Regexp // nil if the parameter has no (valid) expression
+	invalid    bool           // the expression does not compile and therefore never matches
+	target     *radixNode
+}
+
+type radixLeaf struct {
+	route        Route
+	index        int  // position of the Route in its WebService
+	tokenCount   int  // number of path tokens of the Route
+	trailingStar bool // the last token ends with *} which allows for more request tokens
+}
+
+type radixMatch struct {
+	leaf        *radixLeaf
+	paramCount  int
+	staticCount int
+}
+
+func newRadixNode() *radixNode {
+	return &radixNode{literals: map[string]*radixNode{}}
+}
+
+func newRadixTree(ws *WebService) *radixTree {
+	version := atomic.LoadUint32(&ws.routesVersion)
+	tree := &radixTree{version: version, root: newRadixNode()}
+	for i, each := range ws.Routes() {
+		tree.add(i, each)
+	}
+	return tree
+}
+
+func (t *radixTree) add(index int, route Route) {
+	node := t.root
+	for _, token := range route.pathParts {
+		node = node.child(token, route.hasCustomVerb && hasCustomVerb(token))
+	}
+	count := len(route.pathParts)
+	node.leaves = append(node.leaves, &radixLeaf{
+		route:        route,
+		index:        index,
+		tokenCount:   count,
+		trailingStar: count > 0 && strings.HasSuffix(route.pathParts[count-1], "*}"),
+	})
+}
+
+// child returns the node for the route token, creating it if absent.
+func (n *radixNode) child(token string, withVerb bool) *radixNode {
+	if !withVerb && !strings.HasPrefix(token, "{") {
+		next, ok := n.literals[token]
+		if !ok {
+			next = newRadixNode()
+			n.literals[token] = next
+		}
+		return next
+	}
+	for _, each := range n.edges {
+		if each.token == token && (each.verb != "") == withVerb {
+			return each.target
+		}
+	}
+	edge := &radixEdge{token: token, target: newRadixNode()}
+	if withVerb {
+		edge.verb = customVerbReg.FindStringSubmatch(token)[1]
+		token = removeCustomVerb(token)
+	}
+	if !strings.HasPrefix(token, "{") {
+		edge.literal = token
+	} else {
+		edge.isParam = true
+		if colon := strings.Index(token, ":"); colon != -1 {
+			regPart := token[colon+1 : len(token)-1]
+			if regPart == "*" {
+				edge.wildcard = true
+			} else if constraint, ok := pathParameterConstraintNamed(regPart); ok {
+				edge.expression = constraint.matcher
+			} else {
+				compiled, err := regexp.Compile(regPart)
+				edge.expression, edge.invalid = compiled, err != nil
+			}
+		}
+	}
+	n.edges = append(n.edges, edge)
+	return edge.target
+}
+
+// collect appends the leaves of all routes that match the request tokens from offset index.
+func (n *radixNode) collect(requestTokens []string, index, paramCount, staticCount int, matches *[]radixMatch) {
+	for _, each := range n.leaves {
+		if index == len(requestTokens) || each.trailingStar {
+			*matches = append(*matches, radixMatch{each, paramCount, staticCount})
+		}
+	}
+	if index == len(requestTokens) {
+		// reached end of request path
+		return
+	}
+	requestToken := requestTokens[index]
+	if next, ok := n.literals[requestToken]; ok {
+		next.collect(requestTokens, index+1, paramCount, staticCount+1, matches)
+	}
+	for _, each := range n.edges {
+		token, params, statics := requestToken, paramCount, staticCount
+		if each.verb != "" {
+			if !strings.HasSuffix(token, ":"+each.verb) {
+				continue
+			}
+			statics++
+			token = removeCustomVerb(token)
+		}
+		if !each.isParam {
+			if token == each.literal {
+				each.target.collect(requestTokens, index+1, params, statics+1, matches)
+			}
+			continue
+		}
+		params++
+		if each.wildcard {
+			each.target.collectAll(len(requestTokens), params, statics, matches)
+			continue
+		}
+		if each.invalid || (each.expression != nil && !each.expression.MatchString(token)) {
+			continue
+		}
+		each.target.collect(requestTokens, index+1, params, statics, matches)
+	}
+}
+
+// collectAll appends the leaves of all routes below a wildcard token ; these match the remainder of the request path.
+func (n *radixNode) collectAll(requestTokenCount, paramCount, staticCount int, matches *[]radixMatch) {
+	for _, each := range n.leaves {
+		if each.tokenCount >= requestTokenCount || each.trailingStar {
+			*matches = append(*matches, radixMatch{each, paramCount, staticCount})
+		}
+	}
+	for _, next := range n.literals {
+		next.collectAll(requestTokenCount, paramCount, staticCount, matches)
+	}
+	for _, each := range n.edges {
+		each.target.collectAll(requestTokenCount, paramCount, staticCount, matches)
+	}
+}
@@ -0,0 +1,116 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// go test -v -test.run TestRadix_SameAsCurly_routeMatchers ...restful
+func TestRadix_SameAsCurly_routeMatchers(t *testing.T) {
+	for i, each := range routeMatchers {
+		ws := new(WebService)
+		ws.Route(ws.GET(each.route).To(dummy))
+		reqToks := tokenizePath(each.path)
+		curly := CurlyRouter{}.selectRoutes(ws, reqToks)
+		radix := NewRadixRouter().selectRoutes(ws, reqToks)
+		assertSameCurlyRoutes(t, i, each.path, curly, radix)
+		if each.matches && len(radix) == 1 {
+			if got, want := radix[0].paramCount, each.paramCount; got != want {
+				t.Errorf("[%d] unexpected paramCount got:%d want:%d ", i, got, want)
+			}
+			if got, want := radix[0].staticCount, each.staticCount; got != want {
+				t.Errorf("[%d] unexpected staticCount got:%d want:%d ", i, got, want)
+			}
+		}
+	}
+}
+
+// go test -v -test.run TestRadix_SameAsCurly_allRoutes ...restful
+func TestRadix_SameAsCurly_allRoutes(t *testing.T) {
+	ws := new(WebService)
+	for _, each := range routeMatchers {
+		ws.Route(ws.GET(each.route).To(dummy))
+	}
+	ws.Route(ws.GET("/{type}/{id}").To(dummy))
+	ws.Route(ws.GET("/network/{id}").To(dummy))
+	ws.Route(ws.POST("/network/{id}").To(dummy))
+	ws.Route(ws.GET("/static/{var:*}/sub").To(dummy))
+	ws.Route(ws.GET("/a/{x:[a-z]*}").To(dummy))
+	paths := []string{"/network/12", "/static/x", "/static/test/sub", "/static/a/b/c", "/a/bc/de"}
+	for _, each := range routeMatchers {
+		paths = append(paths, each.path)
+	}
+	router := NewRadixRouter()
+	for i, each := range paths {
+		reqToks := tokenizePath(each)
+		assertSameCurlyRoutes(t, i, each, CurlyRouter{}.selectRoutes(ws, reqToks), router.selectRoutes(ws, reqToks))
+	}
+}
+
+func assertSameCurlyRoutes(t *testing.T, i int, path string, curly, radix sortableCurlyRoutes) {
+	if len(curly) != len(radix) {
+		t.Fatalf("[%d] %s: different number of routes, curly:%v radix:%v", i, path, curly.routes(), radix.routes())
+	}
+	for j := range curly {
+		c, r := curly[j], radix[j]
+		if c.route.String() != r.route.String() || c.paramCount != r.paramCount || c.staticCount != r.staticCount {
+			t.Errorf("[%d] %s: different route at %d, curly:%v radix:%v", i, path, j, c, r)
+		}
+	}
+}
+
+// go test -v -test.run TestRadix_ISSUE_34 ...restful
+func TestRadix_ISSUE_34(t *testing.T) {
+	ws1 := new(WebService).Path("/")
+	ws1.Route(ws1.GET("/{type}/{id}").To(dummy))
+	ws1.Route(ws1.GET("/network/{id}").To(dummy))
+	routes := NewRadixRouter().selectRoutes(ws1, tokenizePath("/network/12"))
+	if len(routes) != 2 {
+		t.Fatal("expected 2 routes")
+	}
+	if got, want := routes[0].route.Path, "/network/{id}"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestRadix_ContainerChanges ...restful
+func TestRadix_ContainerChanges(t *testing.T) {
+	container := NewContainer()
+	container.Router(NewRadixRouter())
+	ws1 := new(WebService).Path("/users")
+	ws1.SetDynamicRoutes(true)
+	ws1.Route(ws1.GET("/{id}").To(dummy))
+	container.Add(ws1)
+	ws2 := new(WebService).Path("/orders")
+	ws2.Route(ws2.GET("/{id}").To(dummy))
+	container.Add(ws2)
+
+	if got, want := radixStatus(container, "/orders/1"), http.StatusOK; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	// route added after the WebService was added
+	ws1.Route(ws1.GET("/{id}/orders").To(dummy))
+	if got, want := radixStatus(container, "/users/1/orders"), http.StatusOK; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if err := ws1.RemoveRoute("/users/{id}", "GET"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := radixStatus(container, "/users/1"), http.StatusNotFound; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if err := container.Remove(ws2); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := radixStatus(container, "/orders/1"), http.StatusNotFound; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+func radixStatus(container *Container, path string) int {
+	httpRequest, _ := http.NewRequest("GET", path, nil)
+	httpWriter := httptest.NewRecorder()
+	container.ServeHTTP(httpWriter, httpRequest)
+	return httpWriter.Code
+}
@@ -6,6 +6,7 @@ package restful
 
 import (
 	"compress/zlib"
+	"fmt"
 	"net/http"
 )
 
@@ -17,6 +18,8 @@ type Request struct {
 	pathParameters map[string]string
 	attributes     map[string]interface{} // for storing request-scoped values
 	selectedRoute  *Route                 // is nil when no route was matched
+	// the matrix parameters for each token of the request path, if enabled
+	matrixParameters []map[string][]string
 }
 
 func NewRequest(httpRequest *http.Request) *Request {
@@ -57,6 +60,57 @@ func (r *Request) QueryParameters(name string) []string {
 	return r.Request.URL.Query()[name]
 }
 
+// CookieParameter returns the value of the Cookie by its name or empty if missing
+func (r *Request) CookieParameter(name string) string {
+	cookie, err := r.Request.Cookie(name)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+// QueryParameterValues returns the values of the Query parameter split according to the CollectionFormat
+// of the parameter documented on the selected Route, e.g. ?ids=1,2,3 => [1 2 3] for csv and ?ids=1&ids=2 => [1 2] for multi.
+// Returns a ServiceError with status 400 (Bad Request) if the values violate AllowMultiple, MinItems, MaxItems or UniqueItems.
+// The values of an undocumented parameter are returned as is.
+func (r *Request) QueryParameterValues(name string) ([]string, error) {
+	return r.collectionParameterValues("query", QueryParameterKind, name)
+}
+
+// HeaderParameterValues returns the values of the Header split according to the CollectionFormat
+// of the parameter documented on the selected Route. See QueryParameterValues.
+func (r *Request) HeaderParameterValues(name string) ([]string, error) {
+	return r.collectionParameterValues("header", HeaderParameterKind, name)
+}
+
+// FormParameterValues returns the values of the form parameter (of the parsed body) split according to the CollectionFormat
+// of the parameter documented on the selected Route. See QueryParameterValues.
+func (r *Request) FormParameterValues(name string) ([]string, error) {
+	return r.collectionParameterValues("form", FormParameterKind, name)
+}
+
+func (r *Request) collectionParameterValues(tag string, kind int, name string) ([]string, error) {
+	values := r.parameterValues(tag, name)
+	var doc *Parameter
+	if r.selectedRoute != nil {
+		doc = documentedParameter(r.selectedRoute.ParameterDocs, kind, name)
+		if doc == nil && kind == FormParameterKind {
+			doc = documentedParameter(r.selectedRoute.ParameterDocs, MultiPartFormParameterKind, name)
+		}
+	}
+	if doc == nil {
+		return values, nil
+	}
+	items, problems := collectionItems(*doc.data, values)
+	if len(problems) > 0 {
+		for i, each := range problems {
+			problems[i] = fmt.Sprintf("%s parameter %s: %s", tag, name, each)
+		}
+		return nil, newInvalidParametersError(problems)
+	}
+	return items, nil
+}
+
 // BodyParameter parses the body of the request (once for typically a POST or a PUT) and returns the value of the given name or an error.
 func (r *Request) BodyParameter(name string) (string, error) {
 	err := r.Request.ParseForm()
@@ -32,6 +32,69 @@ func TestQueryParameters(t *testing.T) {
 	}
 }
 
+// go test -v -test.run TestCollectionParameterValues ...restful
+func TestCollectionParameterValues(t *testing.T) {
+	route := &Route{ParameterDocs: []*Parameter{
+		QueryParameter("ids", "").AllowMultiple(true),
+		QueryParameter("tags", "").AllowMultiple(true).CollectionFormat(CollectionFormatMulti).MaxItems(2),
+		QueryParameter("names", "").AllowMultiple(true).CollectionFormat(CollectionFormatSSV).UniqueItems(true),
+		QueryParameter("single", ""),
+		HeaderParameter("X-Codes", "").AllowMultiple(true).CollectionFormat(CollectionFormatPipes).MinItems(2),
+		FormParameter("colors", "").AllowMultiple(true).CollectionFormat(CollectionFormatTSV),
+	}}
+	httpRequest, _ := http.NewRequest("POST", "/?ids=1,2&ids=3&tags=a&tags=b&tags=c&names=x%20y%20x&single=1&single=2&free=a,b", strings.NewReader("colors=red%09blue"))
+	httpRequest.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpRequest.Header.Set("X-Codes", "7")
+	request := NewRequest(httpRequest)
+	request.selectedRoute = route
+
+	tests := []struct {
+		get   func(string) ([]string, error)
+		name  string
+		want  []string
+		error string
+	}{
+		{request.QueryParameterValues, "ids", []string{"1", "2", "3"}, ""},
+		{request.QueryParameterValues, "free", []string{"a,b"}, ""},
+		{request.QueryParameterValues, "missing", nil, ""},
+		{request.QueryParameterValues, "tags", nil, "query parameter tags: got 3 items, maximum is 2"},
+		{request.QueryParameterValues, "names", nil, `query parameter names: duplicate item "x"`},
+		{request.QueryParameterValues, "single", nil, "query parameter single: multiple values are not allowed, got 2"},
+		{request.HeaderParameterValues, "X-Codes", nil, "header parameter X-Codes: got 1 items, minimum is 2"},
+		{request.FormParameterValues, "colors", []string{"red", "blue"}, ""},
+	}
+	for _, tt := range tests {
+		got, err := tt.get(tt.name)
+		if len(tt.error) > 0 {
+			if err == nil || !strings.Contains(err.Error(), tt.error) {
+				t.Errorf("%s: got %v want error %q", tt.name, err, tt.error)
+			}
+			if ser, ok := err.(ServiceError); !ok || ser.Code != http.StatusBadRequest {
+				t.Errorf("%s: got %v want 400 ServiceError", tt.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%s: got %v want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCookieParameter(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "/", nil)
+	httpRequest.AddCookie(&http.Cookie{Name: "session", Value: "s3cr3t"})
+	request := NewRequest(httpRequest)
+	if got, want := request.CookieParameter("session"), "s3cr3t"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := request.CookieParameter("missing"), ""; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
 type Anything map[string]interface{}
 
 type Number struct {
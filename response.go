@@ -21,13 +21,14 @@ var PrettyPrintResponses = true
 // It provides several convenience methods to prepare and write response content.
 type Response struct {
 	http.ResponseWriter
-	requestAccept string        // mime-type what the Http Request says it wants to receive
-	routeProduces []string      // mime-types what the Route says it can produce
-	statusCode    int           // HTTP status code that has been written explicitly (if zero then net/http has written 200)
-	contentLength int           // number of bytes written for the response body
-	prettyPrint   bool          // controls the indentation feature of XML and JSON serialization. It is initialized using var PrettyPrintResponses.
-	err           error         // err property is kept when WriteError is called
-	hijacker      http.Hijacker // if underlying ResponseWriter supports it
+	requestAccept string         // mime-type what the Http Request says it wants to receive
+	routeProduces []string       // mime-types what the Route says it can produce
+	statusCode    int            // HTTP status code that has been written explicitly (if zero then net/http has written 200)
+	contentLength int            // number of bytes written for the response body
+	prettyPrint   bool           // controls the indentation feature of XML and JSON serialization. It is initialized using var PrettyPrintResponses.
+	err           error          // err property is kept when WriteError is called
+	hijacker      http.Hijacker  // if underlying ResponseWriter supports it
+	eventStreams  []*EventStream // streams that are closed when the Route has been dispatched
 }
 
 // NewResponse creates a new response based on a http ResponseWriter.
@@ -84,18 +85,22 @@ func (r *Response) SetRequestAccepts(mime string) {
 func (r *Response) EntityWriter() (EntityReaderWriter, bool) {
 	sorted := sortedMimes(r.requestAccept)
 	for _, eachAccept := range sorted {
+		accepted := parseMediaType(eachAccept.media)
 		for _, eachProduce := range r.routeProduces {
-			if eachProduce == eachAccept.media {
-				if w, ok := entityAccessRegistry.accessorAt(eachAccept.media); ok {
-					return w, true
-				}
+			produced := parseMediaType(eachProduce)
+			if !accepted.matches(produced) {
+				continue
 			}
-		}
-		if eachAccept.media == "*/*" {
-			for _, each := range r.routeProduces {
-				if w, ok := entityAccessRegistry.accessorAt(each); ok {
-					return w, true
+			// the precise media type to write, e.g. application/vnd.acme.order+json
+			contentType := eachProduce
+			if produced.hasWildcard() {
+				if accepted.hasWildcard() {
+					continue
 				}
+				contentType = eachAccept.media
+			}
+			if w, ok := entityAccessRegistry.accessorAt(contentType); ok {
+				return withContentType(w, contentType), true
 			}
 		}
 	}
@@ -0,0 +1,124 @@
+package restful
+
+// Copyright 2024 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// URLFor returns the escaped path (and query) of the Route with the given Operation name in any of its WebServices.
+// Path parameter values must match the expression or constraint of their parameter, e.g. {id:int}.
+// Returns an error if there is no such Route or if a path parameter is missing, unknown or invalid.
+// Values for host parameters of the WebService are rejected because the host is not part of the result.
+//
+//	location, err := container.URLFor("findUser", map[string]string{"id": "42"}, nil) // /users/42
+func (c *Container) URLFor(operation string, pathParams map[string]string, query url.Values) (string, error) {
+	for _, each := range c.RegisteredWebServices() {
+		if route := each.routeWithOperation(operation); route != nil {
+			return buildRouteURL(each, route, pathParams, query)
+		}
+	}
+	return "", fmt.Errorf("no route found with operation:%s", operation)
+}
+
+// URLFor returns the escaped path (and query) of the Route with the given Operation name.
+// Path parameter values must match the expression or constraint of their parameter, e.g. {id:int}.
+// Returns an error if there is no such Route or if a path parameter is missing, unknown or invalid.
+// Values for host parameters, see Host, are rejected because the host is not part of the result.
+func (w *WebService) URLFor(operation string, pathParams map[string]string, query url.Values) (string, error) {
+	route := w.routeWithOperation(operation)
+	if route == nil {
+		return "", fmt.Errorf("no route found with operation:%s in WebService with path:%s", operation, w.rootPath)
+	}
+	return buildRouteURL(w, route, pathParams, query)
+}
+
+// routeWithOperation returns (a copy of) the first Route with the operation name or nil if absent.
+func (w *WebService) routeWithOperation(operation string) *Route {
+	w.routesLock.RLock()
+	defer w.routesLock.RUnlock()
+	for _, each := range w.routes {
+		if each.Operation == operation {
+			route := each
+			return &route
+		}
+	}
+	return nil
+}
+
+// buildRouteURL substitutes the path parameters of the Route path with the escaped values and appends the encoded query.
+func buildRouteURL(webService *WebService, route *Route, pathParams map[string]string, query url.Values) (string, error) {
+	used := map[string]bool{}
+	tokens := strings.Split(strings.TrimLeft(route.Path, "/"), "/")
+	for i, token := range tokens {
+		begin, end := strings.Index(token, "{"), strings.LastIndex(token, "}")
+		if begin == -1 || end < begin {
+			continue
+		}
+		name, expression := splitPathParameterToken(token[begin : end+1])
+		value, ok := pathParams[name]
+		if !ok || len(value) == 0 {
+			return "", fmt.Errorf("missing value for path parameter:%s of route:%v", name, route)
+		}
+		used[name] = true
+		var escaped string
+		if expression == "*" {
+			segments := strings.Split(strings.TrimLeft(value, "/"), "/")
+			for s := range segments {
+				segments[s] = url.PathEscape(segments[s])
+			}
+			escaped = strings.Join(segments, "/")
+		} else {
+			if len(expression) > 0 {
+				matcher, err := regexp.Compile("^(?:" + expression + ")$")
+				if err != nil {
+					return "", fmt.Errorf("invalid expression for path parameter:%s of route:%v", name, route)
+				}
+				if !matcher.MatchString(value) {
+					return "", fmt.Errorf("invalid value %q for path parameter:%s of route:%v", value, name, route)
+				}
+			}
+			escaped = url.PathEscape(value)
+		}
+		tokens[i] = token[:begin] + escaped + token[end+1:]
+	}
+	for name := range pathParams {
+		if used[name] {
+			continue
+		}
+		for _, each := range webService.hosts {
+			for _, hostParam := range each.VarNames {
+				if hostParam == name {
+					return "", fmt.Errorf("host parameter:%s cannot be used, only the path of route:%v is returned", name, route)
+				}
+			}
+		}
+		return "", fmt.Errorf("unknown path parameter:%s for route:%v", name, route)
+	}
+	location := "/" + strings.Join(tokens, "/")
+	if location != "/" && !hasTrailingWildcard(route) {
+		// the Route path may or may not have the declared trailing slash, see TrimRightSlashEnabled
+		location = strings.TrimRight(location, "/")
+		if declaresTrailingSlash(webService, route) {
+			location += "/"
+		}
+	}
+	if len(query) > 0 {
+		location += "?" + query.Encode()
+	}
+	return location, nil
+}
+
+// WriteCreated sets the Location header and writes the value with Http Status Created (201).
+// If the value is nil then only the header and status are written.
+// Typically the location is computed using URLFor.
+func (r *Response) WriteCreated(location string, value interface{}) error {
+	r.Header().Set(HEADER_Location, location)
+	return r.WriteHeaderAndEntity(http.StatusCreated, value)
+}
@@ -0,0 +1,109 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func newReverseRouteService() *WebService {
+	ws := new(WebService).Path("/users")
+	ws.Route(ws.GET("").To(dummy).Operation("listUsers"))
+	ws.Route(ws.GET("/{id:int}").To(dummy).Operation("findUser"))
+	ws.Route(ws.GET("/{id}/orders/").To(dummy).Operation("listOrders"))
+	ws.Route(ws.GET("/{name}/files/{path:*}").To(dummy).Operation("findFile"))
+	ws.Route(ws.POST("/{name}:archive").To(dummy).Operation("archiveUser"))
+	return ws
+}
+
+var reverseRoutes = []struct {
+	operation string
+	params    map[string]string
+	query     url.Values
+	url       string // empty means error
+}{
+	{"listUsers", nil, nil, "/users"},
+	{"listUsers", nil, url.Values{"page": []string{"2"}, "q": []string{"a b"}}, "/users?page=2&q=a+b"},
+	{"findUser", map[string]string{"id": "42"}, nil, "/users/42"},
+	{"findUser", map[string]string{"id": "abc"}, nil, ""},
+	{"findUser", map[string]string{}, nil, ""},
+	{"findUser", map[string]string{"id": "42", "other": "x"}, nil, ""},
+	{"listOrders", map[string]string{"id": "a/b c"}, nil, "/users/a%2Fb%20c/orders/"},
+	{"findFile", map[string]string{"name": "ann", "path": "docs/my file.txt"}, nil, "/users/ann/files/docs/my%20file.txt"},
+	{"findFile", map[string]string{"name": "ann", "path": "docs/"}, nil, "/users/ann/files/docs/"},
+	{"archiveUser", map[string]string{"name": "ann"}, nil, "/users/ann:archive"},
+	{"unknown", nil, nil, ""},
+}
+
+// go test -v -test.run TestWebService_URLFor ...restful
+func TestWebService_URLFor(t *testing.T) {
+	ws := newReverseRouteService()
+	for i, each := range reverseRoutes {
+		got, err := ws.URLFor(each.operation, each.params, each.query)
+		if each.url == "" {
+			if err == nil {
+				t.Errorf("[%d] error expected, got %v", i, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("[%d] unexpected error %v", i, err)
+			continue
+		}
+		if got != each.url {
+			t.Errorf("[%d] got %v want %v", i, got, each.url)
+		}
+	}
+}
+
+// go test -v -test.run TestContainer_URLFor ...restful
+func TestContainer_URLFor(t *testing.T) {
+	wc := NewContainer()
+	wc.Add(newReverseRouteService())
+	location, err := wc.URLFor("findFile", map[string]string{"name": "ann", "path": "docs/a.txt"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the URL must select the route it was built for
+	httpRequest, _ := http.NewRequest("GET", location, nil)
+	_, route, err := wc.router.SelectRoute(wc.RegisteredWebServices(), httpRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := route.Operation, "findFile"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if _, err := wc.URLFor("missing", nil, nil); err == nil {
+		t.Error("error expected")
+	}
+}
+
+// go test -v -test.run TestWebService_URLForRejectsHostParameters ...restful
+func TestWebService_URLForRejectsHostParameters(t *testing.T) {
+	ws := new(WebService).Path("/users").Host("{tenant}.example.com")
+	ws.Route(ws.GET("/{id}").To(dummy).Operation("findUser"))
+	if got, err := ws.URLFor("findUser", map[string]string{"id": "42"}, nil); err != nil || got != "/users/42" {
+		t.Errorf("got %v,%v want /users/42", got, err)
+	}
+	_, err := ws.URLFor("findUser", map[string]string{"id": "42", "tenant": "acme"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "host parameter:tenant") {
+		t.Error("host parameter error expected, got", err)
+	}
+}
+
+func TestResponse_WriteCreated(t *testing.T) {
+	httpWriter := httptest.NewRecorder()
+	resp := NewResponse(httpWriter)
+	resp.SetRequestAccepts(MIME_JSON)
+	if err := resp.WriteCreated("/users/42", map[string]string{"id": "42"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := httpWriter.Code, http.StatusCreated; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := httpWriter.Header().Get("Location"), "/users/42"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
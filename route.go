@@ -52,6 +52,19 @@ type Route struct {
 	//Overrides the container.contentEncodingEnabled
 	contentEncodingEnabled *bool
 
+	//Overrides the container.parameterValidationEnabled
+	parameterValidationEnabled *bool
+
+	//Overrides the container.bodyValidationEnabled
+	bodyValidationEnabled *bool
+
+	// limits for a multipart/form-data body, nil means the defaults
+	multipartLimits *MultipartLimits
+
+	// Overrides the webService.strictQueryParameters and webService.strictHeaderParameters
+	strictQueryParameters  *bool
+	strictHeaderParameters *bool
+
 	// indicate route path has custom verb
 	hasCustomVerb bool
 
@@ -82,6 +95,16 @@ func stringTrimSpaceCutset(r rune) bool {
 	return r == ' '
 }
 
+// passesConditions returns whether all the If functions of the Route accept the request.
+func (r Route) passesConditions(httpRequest *http.Request) bool {
+	for _, fn := range r.If {
+		if !fn(httpRequest) {
+			return false
+		}
+	}
+	return true
+}
+
 // Return whether the mimeType matches to what this Route can produce.
 func (r Route) matchesAccept(mimeTypesWithQuality string) bool {
 	remaining := mimeTypesWithQuality
@@ -92,15 +115,13 @@ func (r Route) matchesAccept(mimeTypesWithQuality string) bool {
 		} else {
 			mimeType, remaining = remaining[:end], remaining[end+1:]
 		}
-		if quality := strings.Index(mimeType, ";"); quality != -1 {
-			mimeType = mimeType[:quality]
-		}
-		mimeType = strings.TrimFunc(mimeType, stringTrimSpaceCutset)
-		if mimeType == "*/*" {
+		// wildcards, parameters (e.g. version=2) and the quality are handled by matches
+		accepted := parseMediaType(strings.TrimFunc(mimeType, stringTrimSpaceCutset))
+		if accepted.mainType == "*" {
 			return true
 		}
 		for _, producibleType := range r.Produces {
-			if producibleType == "*/*" || producibleType == mimeType {
+			if accepted.matches(parseMediaType(producibleType)) {
 				return true
 			}
 		}
@@ -145,12 +166,10 @@ func (r Route) matchesContentType(mimeTypes string) bool {
 		} else {
 			mimeType, remaining = remaining[:end], remaining[end+1:]
 		}
-		if quality := strings.Index(mimeType, ";"); quality != -1 {
-			mimeType = mimeType[:quality]
-		}
-		mimeType = strings.TrimFunc(mimeType, stringTrimSpaceCutset)
+		// wildcards and parameters (e.g. charset) are handled by matches
+		contentType := parseMediaType(strings.TrimFunc(mimeType, stringTrimSpaceCutset))
 		for _, consumeableType := range r.Consumes {
-			if consumeableType == "*/*" || consumeableType == mimeType {
+			if contentType.matches(parseMediaType(consumeableType)) {
 				return true
 			}
 		}
@@ -160,6 +179,26 @@ func (r Route) matchesContentType(mimeTypes string) bool {
 	}
 }
 
+// allowedMethods returns the distinct HTTP methods of the routes in order of appearance
+func allowedMethods(routes []*Route) []string {
+	methods := []string{}
+	for _, each := range routes {
+		if !containsMethod(methods, each.Method) {
+			methods = append(methods, each.Method)
+		}
+	}
+	return methods
+}
+
+func containsMethod(methods []string, method string) bool {
+	for _, each := range methods {
+		if each == method {
+			return true
+		}
+	}
+	return false
+}
+
 // Tokenize an URL path using the slash separator ; the result does not have empty tokens
 func tokenizePath(path string) []string {
 	if "/" == path {
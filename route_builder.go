@@ -31,18 +31,24 @@ type RouteBuilder struct {
 	typeNameHandleFunc TypeNameHandleFunction // required
 
 	// documentation
-	doc                    string
-	notes                  string
-	operation              string
-	readSample             interface{}
-	writeSamples           []interface{}
-	parameters             []*Parameter
-	errorMap               map[int]ResponseError
-	defaultResponse        *ResponseError
-	metadata               map[string]interface{}
-	extensions             map[string]interface{}
-	deprecated             bool
-	contentEncodingEnabled *bool
+	doc                        string
+	notes                      string
+	operation                  string
+	readSample                 interface{}
+	writeSamples               []interface{}
+	parameters                 []*Parameter
+	errorMap                   map[int]ResponseError
+	defaultResponse            *ResponseError
+	metadata                   map[string]interface{}
+	extensions                 map[string]interface{}
+	deprecated                 bool
+	contentEncodingEnabled     *bool
+	parameterValidationEnabled *bool
+	bodyValidationEnabled      *bool
+	multipartLimits            *MultipartLimits
+	strictQueryParameters      *bool
+	strictHeaderParameters     *bool
+	group                      *RouteGroup // if created by a RouteGroup
 }
 
 // Do evaluates each argument with the RouteBuilder itself.
@@ -310,8 +316,18 @@ func (b *RouteBuilder) typeNameHandler(handler TypeNameHandleFunction) *RouteBui
 	return b
 }
 
-// Build creates a new Route using the specification details collected by the RouteBuilder
+// applyGroup applies the defaults of the RouteGroup that created the RouteBuilder, if any, only once.
+func (b *RouteBuilder) applyGroup() {
+	if b.group != nil {
+		b.group.applyTo(b)
+		b.group = nil
+	}
+}
+
+// Build creates a new Route using the specification details collected by the RouteBuilder.
+// If the RouteBuilder was created by a RouteGroup then its path, filters, parameters and other defaults are applied.
 func (b *RouteBuilder) Build() Route {
+	b.applyGroup()
 	pathExpr, err := newPathExpression(b.currentPath)
 	if err != nil {
 		log.Printf("Invalid path:%s because:%v", b.currentPath, err)
@@ -347,6 +363,11 @@ func (b *RouteBuilder) Build() Route {
 		Metadata:                         b.metadata,
 		Deprecated:                       b.deprecated,
 		contentEncodingEnabled:           b.contentEncodingEnabled,
+		parameterValidationEnabled:       b.parameterValidationEnabled,
+		bodyValidationEnabled:            b.bodyValidationEnabled,
+		multipartLimits:                  b.multipartLimits,
+		strictQueryParameters:            b.strictQueryParameters,
+		strictHeaderParameters:           b.strictHeaderParameters,
 		allowedMethodsWithoutContentType: b.allowedMethodsWithoutContentType,
 	}
 	// set WriteSample if one specified
@@ -354,6 +375,7 @@ func (b *RouteBuilder) Build() Route {
 		route.WriteSample = b.writeSamples[0]
 	}
 	route.Extensions = b.extensions
+	applyPathParameterConstraints(route.Path, route.ParameterDocs)
 	route.postBuild()
 	return route
 }
@@ -0,0 +1,170 @@
+package restful
+
+// Copyright 2024 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// RouteConflictIdentical = indicator of two Routes with the same method, path, consumes and produces.
+	RouteConflictIdentical = "identical"
+
+	// RouteConflictShadowed = indicator of two Routes with the same method and path template
+	// except for the names of their path parameters, e.g. /users/{id} and /users/{name}.
+	RouteConflictShadowed = "shadowed"
+
+	// RouteConflictOverlappingExpression = indicator of two Routes that only differ in a path parameter
+	// for which one has a regular expression (or constraint) and the other has none, e.g. /users/{id:int} and /users/{name}.
+	RouteConflictOverlappingExpression = "overlapping-expression"
+)
+
+// RouteConflictPolicy determines what Add does with the Routes of a WebService that conflict with Routes already added.
+type RouteConflictPolicy int
+
+const (
+	// RouteConflictsIgnored does not check Routes when adding a WebService ; use RouteConflicts or CheckRouteConflicts.
+	RouteConflictsIgnored RouteConflictPolicy = iota
+	// RouteConflictsLogged logs each conflict of the Routes of the WebService that is added.
+	RouteConflictsLogged
+	// RouteConflictsRejected logs the conflicts and exits, as Add does for a duplicate root path.
+	RouteConflictsRejected
+)
+
+// RouteConflictPolicy sets whether Add checks the Routes of a WebService for conflicts (default=RouteConflictsIgnored).
+// Each WebService is compared with those added before, so the check takes longer with every WebService.
+// Routes added to a WebService after it was added to the Container are not checked.
+func (c *Container) RouteConflictPolicy(policy RouteConflictPolicy) {
+	c.routeConflictPolicy = policy
+}
+
+// RouteConflict describes two Routes that match the same requests such that the router silently selects one of them.
+// Routes with different methods, non-overlapping Consumes or Produces, or with conditions (If) never conflict.
+type RouteConflict struct {
+	Kind  string // one of RouteConflictIdentical, RouteConflictShadowed or RouteConflictOverlappingExpression
+	Route string // method and path of the Route that was added last, e.g. "GET /users/{name}"
+	Other string // method and path of the Route that was added before
+}
+
+// String returns a text representation of the conflict
+func (c RouteConflict) String() string {
+	return fmt.Sprintf("%s conflicts with %s (%s)", c.Route, c.Other, c.Kind)
+}
+
+// RouteConflictError is returned by CheckRouteConflicts and lists all detected conflicts.
+type RouteConflictError struct {
+	Conflicts []RouteConflict
+}
+
+// Error returns a text representation of all conflicts
+func (e RouteConflictError) Error() string {
+	lines := make([]string, 0, len(e.Conflicts))
+	for _, each := range e.Conflicts {
+		lines = append(lines, each.String())
+	}
+	return "route conflicts detected: " + strings.Join(lines, "; ")
+}
+
+// routeConflictsWith returns the conflicts of a Route with each of the others.
+func routeConflictsWith(route Route, others []Route) (conflicts []RouteConflict) {
+	for i := range others {
+		if kind, ok := routeConflictKind(route, others[i]); ok {
+			conflicts = append(conflicts, RouteConflict{Kind: kind, Route: route.String(), Other: others[i].String()})
+		}
+	}
+	return
+}
+
+// routeConflictsAmong returns the conflicts between all pairs of Routes.
+func routeConflictsAmong(routes []Route) (conflicts []RouteConflict) {
+	for i := 1; i < len(routes); i++ {
+		conflicts = append(conflicts, routeConflictsWith(routes[i], routes[:i])...)
+	}
+	return
+}
+
+// routeConflictKind compares the templates of two Routes and returns whether and how they conflict.
+func routeConflictKind(route, other Route) (string, bool) {
+	if route.Method != other.Method || len(route.If) > 0 || len(other.If) > 0 {
+		return "", false
+	}
+	if !mimeTypesOverlap(route.Consumes, other.Consumes) || !mimeTypesOverlap(route.Produces, other.Produces) {
+		return "", false
+	}
+	tokens, otherTokens := tokenizePath(route.Path), tokenizePath(other.Path)
+	if len(tokens) != len(otherTokens) {
+		return "", false
+	}
+	renamed, overlapping := false, false
+	for i, each := range tokens {
+		otherToken := otherTokens[i]
+		if hasCustomVerb(each) || hasCustomVerb(otherToken) {
+			if customVerbReg.FindString(each) != customVerbReg.FindString(otherToken) {
+				return "", false
+			}
+			each, otherToken = removeCustomVerb(each), removeCustomVerb(otherToken)
+		}
+		isParam, otherIsParam := strings.HasPrefix(each, "{"), strings.HasPrefix(otherToken, "{")
+		if !isParam && !otherIsParam {
+			if each != otherToken {
+				return "", false
+			}
+			continue
+		}
+		if isParam != otherIsParam {
+			// a static token is always preferred over a parameter
+			return "", false
+		}
+		name, expression := splitPathParameterToken(each)
+		otherName, otherExpression := splitPathParameterToken(otherToken)
+		if expression != otherExpression {
+			if expression != "" && otherExpression != "" {
+				// different expressions are assumed to match different values
+				return "", false
+			}
+			overlapping = true
+		}
+		if name != otherName {
+			renamed = true
+		}
+	}
+	if overlapping {
+		return RouteConflictOverlappingExpression, true
+	}
+	if renamed {
+		return RouteConflictShadowed, true
+	}
+	return RouteConflictIdentical, true
+}
+
+// splitPathParameterToken returns the name and the (resolved) expression of a path parameter token.
+func splitPathParameterToken(token string) (name, expression string) {
+	inner := strings.TrimSuffix(strings.TrimPrefix(token, "{"), "}")
+	colon := strings.Index(inner, ":")
+	if colon == -1 {
+		return strings.TrimSpace(inner), ""
+	}
+	name, expression = strings.TrimSpace(inner[:colon]), strings.TrimSpace(inner[colon+1:])
+	if constraint, ok := pathParameterConstraintNamed(expression); ok {
+		expression = constraint.Expression
+	}
+	return
+}
+
+// mimeTypesOverlap returns whether two lists of MIME types have one in common ; an empty list means any.
+func mimeTypesOverlap(some, others []string) bool {
+	if len(some) == 0 || len(others) == 0 {
+		return true
+	}
+	for _, each := range some {
+		for _, other := range others {
+			if each == other || each == "*/*" || other == "*/*" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+package restful
+
+import (
+	"bytes"
+	stdlog "log"
+	"net/http"
+	"testing"
+
+	"github.com/emicklei/go-restful/v3/log"
+)
+
+var routeConflicts = []struct {
+	method, path           string
+	otherMethod, otherPath string
+	kind                   string // empty means no conflict
+}{
+	{"GET", "/users/{id}", "GET", "/users/{id}", RouteConflictIdentical},
+	{"GET", "/users/{id}", "GET", "/users/{name}", RouteConflictShadowed},
+	{"GET", "/users/{id:int}", "GET", "/users/{name}", RouteConflictOverlappingExpression},
+	{"GET", "/users/{id:int}", "GET", "/users/{id:[-+]?[0-9]+}", RouteConflictIdentical},
+	{"GET", "/users/{id:int}", "GET", "/users/{name:alpha}", ""},
+	{"GET", "/users/{id}", "POST", "/users/{id}", ""},
+	{"GET", "/users/{id}", "GET", "/users/me", ""},
+	{"GET", "/users/{id}", "GET", "/users/{id}/orders", ""},
+	{"GET", "/users/{id}:run", "GET", "/users/{name}:run", RouteConflictShadowed},
+	{"GET", "/users/{id}:run", "GET", "/users/{id}:stop", ""},
+}
+
+// go test -v -test.run TestRouteConflictKind ...restful
+func TestRouteConflictKind(t *testing.T) {
+	for i, each := range routeConflicts {
+		ws := new(WebService).Path("/")
+		ws.Route(ws.Method(each.otherMethod).Path(each.otherPath).To(dummy))
+		ws.Route(ws.Method(each.method).Path(each.path).To(dummy))
+		conflicts := ws.RouteConflicts()
+		if each.kind == "" {
+			if len(conflicts) != 0 {
+				t.Errorf("[%d] unexpected conflicts %v", i, conflicts)
+			}
+			continue
+		}
+		if len(conflicts) != 1 {
+			t.Fatalf("[%d] expected one conflict, got %v", i, conflicts)
+		}
+		if got, want := conflicts[0].Kind, each.kind; got != want {
+			t.Errorf("[%d] got %v want %v", i, got, want)
+		}
+		if got, want := conflicts[0].Route, each.method+" "+each.path; got != want {
+			t.Errorf("[%d] got %v want %v", i, got, want)
+		}
+	}
+}
+
+// go test -v -test.run TestRouteConflict_MediaTypesAndConditions ...restful
+func TestRouteConflict_MediaTypesAndConditions(t *testing.T) {
+	ws := new(WebService).Path("/")
+	ws.Route(ws.POST("/a").Consumes(MIME_JSON).To(dummy))
+	ws.Route(ws.POST("/a").Consumes(MIME_XML).To(dummy))
+	ws.Route(ws.GET("/b").Produces(MIME_JSON).To(dummy))
+	ws.Route(ws.GET("/b").Produces(MIME_XML).To(dummy))
+	ws.Route(ws.GET("/c").To(dummy))
+	ws.Route(ws.GET("/c").If(func(*http.Request) bool { return true }).To(dummy))
+	if err := ws.CheckRouteConflicts(); err != nil {
+		t.Error(err)
+	}
+	ws.Route(ws.POST("/a").Consumes(MIME_XML, MIME_JSON).To(dummy))
+	err := ws.CheckRouteConflicts()
+	if err == nil {
+		t.Fatal("error expected")
+	}
+	if got, want := len(err.(RouteConflictError).Conflicts), 2; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestContainer_RouteConflicts ...restful
+func TestContainer_RouteConflicts(t *testing.T) {
+	wc := NewContainer()
+	ws1 := new(WebService).Path("/users")
+	ws1.Route(ws1.GET("/{id}").To(dummy))
+	wc.Add(ws1)
+	ws2 := new(WebService).Path("/{kind}")
+	ws2.Route(ws2.GET("/{name}").To(dummy))
+	ws2.Route(ws2.GET("/{id}").To(dummy))
+	wc.Add(ws2)
+	conflicts := wc.RouteConflicts()
+	if got, want := len(conflicts), 1; got != want {
+		t.Fatalf("got %v want %v, %v", got, want, conflicts)
+	}
+	if got, want := conflicts[0].String(), "GET /{kind}/{id} conflicts with GET /{kind}/{name} (shadowed)"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if wc.CheckRouteConflicts() == nil {
+		t.Error("error expected")
+	}
+}
+
+// go test -v -test.run TestRouteConflictPolicy_Logged ...restful
+func TestRouteConflictPolicy_Logged(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	defer log.SetLogger(log.Logger)
+	log.SetLogger(stdlog.New(buffer, "", 0))
+
+	wc := NewContainer()
+	users := new(WebService).Path("/")
+	users.Route(users.GET("/users/{id}").To(dummy))
+	wc.Add(users)
+	if buffer.Len() != 0 {
+		t.Fatal("no conflicts expected, got", buffer.String())
+	}
+	wc.RouteConflictPolicy(RouteConflictsLogged)
+	shadowing := new(WebService).Path("/users/{name}")
+	shadowing.Route(shadowing.GET("").To(dummy))
+	wc.Add(shadowing)
+	if got, want := buffer.String(), "route conflict detected: GET /users/{name} conflicts with GET /users/{id} (shadowed)\n"; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+	if got, want := len(wc.RegisteredWebServices()), 2; got != want {
+		t.Errorf("logged conflicts must not prevent adding, got %v want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestRouteConflictPolicy_Ignored ...restful
+func TestRouteConflictPolicy_Ignored(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	defer log.SetLogger(log.Logger)
+	log.SetLogger(stdlog.New(buffer, "", 0))
+
+	wc := NewContainer()
+	ws := new(WebService).Path("/users")
+	ws.Route(ws.GET("/{id}").To(dummy))
+	ws.Route(ws.GET("/{name}").To(dummy))
+	wc.Add(ws)
+	if buffer.Len() != 0 {
+		t.Error("nothing logged by default, got", buffer.String())
+	}
+}
+
+// go test -v -test.run TestContainer_routeConflictsOf ...restful
+func TestContainer_routeConflictsOf(t *testing.T) {
+	wc := NewContainer()
+	users := new(WebService).Path("/")
+	users.Route(users.GET("/users/{id}").To(dummy))
+	wc.Add(users)
+
+	added := new(WebService).Path("/users/{name}")
+	added.Route(added.GET("").To(dummy))
+	added.Route(added.PUT("").To(dummy))
+	added.Route(added.PUT("").To(dummy))
+	conflicts := wc.routeConflictsOf(added)
+	if len(conflicts) != 2 {
+		t.Fatalf("conflicts within and with other WebServices expected, got %v", conflicts)
+	}
+	if got, want := conflicts[0].Kind, RouteConflictIdentical; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := conflicts[1].Other, "GET /users/{id}"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
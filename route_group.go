@@ -0,0 +1,197 @@
+package restful
+
+// Copyright 2024 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import "strings"
+
+// RouteGroup is used to declare Routes of a WebService that share a sub path, filters,
+// Produces, Consumes, parameters and metadata. Groups can be nested.
+// The Routes built inside a group are the same as when all of these were declared on each RouteBuilder.
+//
+//	orgs := ws.Group("/orgs/{orgId}").
+//		Filter(authenticate).
+//		Param(ws.PathParameter("orgId", "identifier of the organization"))
+//	orgs.Route(orgs.GET("/members").To(listMembers))    // GET /{root}/orgs/{orgId}/members
+//	orgs.Route(orgs.POST("/members").To(createMember))
+type RouteGroup struct {
+	webService *WebService
+	parent     *RouteGroup
+	subPath    string
+	filters    []FilterFunction
+	produces   []string
+	consumes   []string
+	parameters []*Parameter
+	metadata   map[string]interface{}
+}
+
+// Group returns a new RouteGroup for declaring Routes with a sub path relative to the root path of the WebService.
+func (w *WebService) Group(subPath string) *RouteGroup {
+	return &RouteGroup{webService: w, subPath: subPath}
+}
+
+// Group returns a new RouteGroup nested inside this group, with a sub path relative to this group.
+// A nested group inherits all the defaults of its parent groups.
+func (g *RouteGroup) Group(subPath string) *RouteGroup {
+	return &RouteGroup{webService: g.webService, parent: g, subPath: subPath}
+}
+
+// Path returns the sub path of this group relative to the root path of the WebService.
+func (g *RouteGroup) Path() string {
+	if g.parent == nil {
+		return g.subPath
+	}
+	return joinGroupPath(g.parent.Path(), g.subPath)
+}
+
+// Filter appends a FilterFunction to the filters of each Route in this group.
+// These are called after the filters of any parent group and before those of the Route itself.
+func (g *RouteGroup) Filter(filter FilterFunction) *RouteGroup {
+	g.filters = append(g.filters, filter)
+	return g
+}
+
+// Produces specifies the MIME types for each Route in this group that does not specify them itself.
+func (g *RouteGroup) Produces(contentTypes ...string) *RouteGroup {
+	g.produces = contentTypes
+	return g
+}
+
+// Consumes specifies the MIME types for each Route in this group that does not specify them itself.
+func (g *RouteGroup) Consumes(accepts ...string) *RouteGroup {
+	g.consumes = accepts
+	return g
+}
+
+// Param documents a parameter, typically a path parameter of the group path, for each Route in this group.
+// A Route that documents a parameter with the same name keeps its own.
+func (g *RouteGroup) Param(parameter *Parameter) *RouteGroup {
+	g.parameters = append(g.parameters, parameter)
+	return g
+}
+
+// Metadata adds or updates a key=value pair to the metadata of each Route in this group.
+// A Route that has metadata with the same key keeps its own value.
+func (g *RouteGroup) Metadata(key string, value interface{}) *RouteGroup {
+	if g.metadata == nil {
+		g.metadata = map[string]interface{}{}
+	}
+	g.metadata[key] = value
+	return g
+}
+
+// Route creates a new Route using the RouteBuilder and adds it to the WebService of this group.
+func (g *RouteGroup) Route(builder *RouteBuilder) *RouteGroup {
+	g.webService.Route(builder)
+	return g
+}
+
+// Method creates a new RouteBuilder for this group and initialize its http method.
+// The path of the RouteBuilder is relative to the path of the group.
+func (g *RouteGroup) Method(httpMethod string) *RouteBuilder {
+	b := g.webService.Method(httpMethod)
+	b.group = g
+	return b
+}
+
+// HEAD is a shortcut for .Method("HEAD").Path(subPath) inside this group
+func (g *RouteGroup) HEAD(subPath string) *RouteBuilder {
+	return g.routeBuilder("HEAD", subPath)
+}
+
+// GET is a shortcut for .Method("GET").Path(subPath) inside this group
+func (g *RouteGroup) GET(subPath string) *RouteBuilder {
+	return g.routeBuilder("GET", subPath)
+}
+
+// POST is a shortcut for .Method("POST").Path(subPath) inside this group
+func (g *RouteGroup) POST(subPath string) *RouteBuilder {
+	return g.routeBuilder("POST", subPath)
+}
+
+// PUT is a shortcut for .Method("PUT").Path(subPath) inside this group
+func (g *RouteGroup) PUT(subPath string) *RouteBuilder {
+	return g.routeBuilder("PUT", subPath)
+}
+
+// PATCH is a shortcut for .Method("PATCH").Path(subPath) inside this group
+func (g *RouteGroup) PATCH(subPath string) *RouteBuilder {
+	return g.routeBuilder("PATCH", subPath)
+}
+
+// DELETE is a shortcut for .Method("DELETE").Path(subPath) inside this group
+func (g *RouteGroup) DELETE(subPath string) *RouteBuilder {
+	return g.routeBuilder("DELETE", subPath)
+}
+
+// OPTIONS is a shortcut for .Method("OPTIONS").Path(subPath) inside this group
+func (g *RouteGroup) OPTIONS(subPath string) *RouteBuilder {
+	return g.routeBuilder("OPTIONS", subPath)
+}
+
+func (g *RouteGroup) routeBuilder(httpMethod, subPath string) *RouteBuilder {
+	return g.Method(httpMethod).Path(subPath)
+}
+
+// lineage returns the groups from the outermost to this group.
+func (g *RouteGroup) lineage() []*RouteGroup {
+	if g.parent == nil {
+		return []*RouteGroup{g}
+	}
+	return append(g.parent.lineage(), g)
+}
+
+// applyTo prefixes the path of the RouteBuilder with the group path and copies the defaults of this group and its parents.
+// The RouteBuilder keeps what it specifies itself.
+func (g *RouteGroup) applyTo(b *RouteBuilder) {
+	b.currentPath = joinGroupPath(g.Path(), b.currentPath)
+	groups := g.lineage()
+	filters := []FilterFunction{}
+	parameters := []*Parameter{}
+	names := map[string]bool{}
+	for _, each := range b.parameters {
+		names[each.Data().Name] = true
+	}
+	for _, each := range groups {
+		filters = append(filters, each.filters...)
+		for _, param := range each.parameters {
+			if !names[param.Data().Name] {
+				names[param.Data().Name] = true
+				parameters = append(parameters, param)
+			}
+		}
+	}
+	if len(filters) > 0 {
+		b.filters = append(filters, b.filters...)
+	}
+	if len(parameters) > 0 {
+		b.parameters = append(parameters, b.parameters...)
+	}
+	// the innermost group wins
+	for i := len(groups) - 1; i >= 0; i-- {
+		each := groups[i]
+		if len(b.produces) == 0 {
+			b.produces = each.produces
+		}
+		if len(b.consumes) == 0 {
+			b.consumes = each.consumes
+		}
+		for key, value := range each.metadata {
+			if _, ok := b.metadata[key]; !ok {
+				b.Metadata(key, value)
+			}
+		}
+	}
+}
+
+// joinGroupPath appends the sub path to the group path without adding a trailing slash, e.g. /orgs/{id} + "" => /orgs/{id}
+func joinGroupPath(groupPath, subPath string) string {
+	if subPath == "" || subPath == "/" {
+		return groupPath
+	}
+	if groupPath == "" {
+		return subPath
+	}
+	return strings.TrimRight(groupPath, "/") + "/" + strings.TrimLeft(subPath, "/")
+}
@@ -0,0 +1,126 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// go test -v -test.run TestRouteGroup_SameAsByHand ...restful
+func TestRouteGroup_SameAsByHand(t *testing.T) {
+	orgId := PathParameter("orgId", "identifier of the organization")
+	memberId := PathParameter("memberId", "identifier of the member")
+
+	grouped := new(WebService).Path("/api")
+	orgs := grouped.Group("/orgs/{orgId}").Filter(noopFilter).Produces(MIME_JSON).Param(orgId).Metadata("tag", "orgs")
+	orgs.Route(orgs.GET("").To(dummy).Operation("getOrg"))
+	members := orgs.Group("/members").Consumes(MIME_JSON).Metadata("tag", "members")
+	members.Route(members.GET("/{memberId}").Param(memberId).To(dummy).Operation("getMember"))
+	members.Route(members.POST("/").Produces(MIME_XML).Metadata("tag", "new").To(dummy).Operation("addMember"))
+
+	byHand := new(WebService).Path("/api")
+	byHand.Route(byHand.GET("/orgs/{orgId}").Filter(noopFilter).Produces(MIME_JSON).Param(orgId).Metadata("tag", "orgs").
+		To(dummy).Operation("getOrg"))
+	byHand.Route(byHand.GET("/orgs/{orgId}/members/{memberId}").Filter(noopFilter).Produces(MIME_JSON).Consumes(MIME_JSON).Param(orgId).Param(memberId).Metadata("tag", "members").
+		To(dummy).Operation("getMember"))
+	byHand.Route(byHand.POST("/orgs/{orgId}/members").Filter(noopFilter).Produces(MIME_XML).Consumes(MIME_JSON).Param(orgId).Metadata("tag", "new").
+		To(dummy).Operation("addMember"))
+
+	got, want := grouped.Routes(), byHand.Routes()
+	if len(got) != len(want) {
+		t.Fatalf("got %d want %d routes", len(got), len(want))
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.Method != w.Method || g.Path != w.Path || g.relativePath != w.relativePath {
+			t.Errorf("[%d] got %s %s want %s %s", i, g.Method, g.Path, w.Method, w.Path)
+		}
+		if !reflect.DeepEqual(g.Produces, w.Produces) || !reflect.DeepEqual(g.Consumes, w.Consumes) {
+			t.Errorf("[%d] got %v %v want %v %v", i, g.Produces, g.Consumes, w.Produces, w.Consumes)
+		}
+		if !reflect.DeepEqual(g.ParameterDocs, w.ParameterDocs) {
+			t.Errorf("[%d] got %v want %v", i, g.ParameterDocs, w.ParameterDocs)
+		}
+		if !reflect.DeepEqual(g.Metadata, w.Metadata) {
+			t.Errorf("[%d] got %v want %v", i, g.Metadata, w.Metadata)
+		}
+		if len(g.Filters) != len(w.Filters) {
+			t.Errorf("[%d] got %d want %d filters", i, len(g.Filters), len(w.Filters))
+		}
+	}
+}
+
+// go test -v -test.run TestRouteGroup_Dispatch ...restful
+func TestRouteGroup_Dispatch(t *testing.T) {
+	ws := new(WebService).Path("/api")
+	calls := []string{}
+	orgs := ws.Group("/orgs/{orgId}").Filter(func(req *Request, resp *Response, chain *FilterChain) {
+		calls = append(calls, "orgs")
+		chain.ProcessFilter(req, resp)
+	})
+	members := orgs.Group("members").Filter(func(req *Request, resp *Response, chain *FilterChain) {
+		calls = append(calls, "members")
+		chain.ProcessFilter(req, resp)
+	})
+	members.Route(members.GET("/{memberId}").Filter(func(req *Request, resp *Response, chain *FilterChain) {
+		calls = append(calls, "route")
+		chain.ProcessFilter(req, resp)
+	}).To(func(req *Request, resp *Response) {
+		resp.Write([]byte(req.PathParameter("orgId") + "/" + req.PathParameter("memberId")))
+	}).Operation("getMember"))
+	wc := NewContainer()
+	wc.Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "/api/orgs/acme/members/42", nil)
+	httpWriter := httptest.NewRecorder()
+	wc.Dispatch(httpWriter, httpRequest)
+	if got, want := httpWriter.Body.String(), "acme/42"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := calls, []string{"orgs", "members", "route"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestRouteGroup_Build ...restful
+func TestRouteGroup_Build(t *testing.T) {
+	ws := new(WebService).Path("/api")
+	orgs := ws.Group("/orgs/{orgId}").Filter(noopFilter).Produces(MIME_JSON).
+		Param(PathParameter("orgId", "identifier of the organization")).Metadata("tag", "orgs")
+	builder := orgs.GET("/members").Doc("list the members").To(dummy).Operation("listMembers")
+	route := builder.Build()
+	if got, want := route.Path, "/api/orgs/{orgId}/members"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := route.Produces, []string{MIME_JSON}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if len(route.Filters) != 1 || len(route.ParameterDocs) != 1 || route.Metadata["tag"] != "orgs" {
+		t.Errorf("group defaults expected, got %d filters, %d parameters and metadata %v", len(route.Filters), len(route.ParameterDocs), route.Metadata)
+	}
+	if route.Doc != "list the members" {
+		t.Error("the Route keeps its own documentation, got", route.Doc)
+	}
+	// the group is applied only once
+	ws.Route(builder)
+	if again := ws.Routes()[0]; again.Path != route.Path || len(again.Filters) != 1 || len(again.ParameterDocs) != 1 {
+		t.Errorf("got %s with %d filters and %d parameters", again.Path, len(again.Filters), len(again.ParameterDocs))
+	}
+}
+
+func TestJoinGroupPath(t *testing.T) {
+	for _, each := range [][3]string{
+		{"/orgs/{id}", "", "/orgs/{id}"},
+		{"/orgs/{id}", "/", "/orgs/{id}"},
+		{"/orgs/{id}/", "/members", "/orgs/{id}/members"},
+		{"/orgs/{id}", "members/", "/orgs/{id}/members/"},
+		{"", "/members", "/members"},
+	} {
+		if got := joinGroupPath(each[0], each[1]); got != each[2] {
+			t.Errorf("got %v want %v", got, each[2])
+		}
+	}
+}
+
+func noopFilter(req *Request, resp *Response, chain *FilterChain) { chain.ProcessFilter(req, resp) }
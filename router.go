@@ -18,3 +18,9 @@ type RouteSelector interface {
 		webServices []*WebService,
 		httpRequest *http.Request) (selectedService *WebService, selected *Route, err error)
 }
+
+// compilingRouteSelector is implemented by RouteSelectors that precompile the Routes of the WebServices.
+// The Container calls compile whenever its collection of WebServices changes.
+type compilingRouteSelector interface {
+	compile(webServices []*WebService)
+}
@@ -7,6 +7,7 @@ package restful
 import (
 	"fmt"
 	"net/http"
+	"strings"
 )
 
 // ServiceError is a transport object to pass information about a non-Http error occurred in a WebService while processing a request.
@@ -30,3 +31,35 @@ func NewErrorWithHeader(code int, message string, header http.Header) ServiceErr
 func (s ServiceError) Error() string {
 	return fmt.Sprintf("[ServiceError:%v] %v", s.Code, s.Message)
 }
+
+// AllowedMethods returns the HTTP methods listed in the Allow header, e.g. of a 405 (Method Not Allowed) error.
+// Returns nil if there is no such header.
+func (s ServiceError) AllowedMethods() []string {
+	allow := s.Header.Get(HEADER_Allow)
+	if len(allow) == 0 {
+		return nil
+	}
+	methods := []string{}
+	for _, each := range strings.Split(allow, ",") {
+		if method := strings.TrimSpace(each); len(method) > 0 {
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
+
+// newMethodNotAllowedError returns a 405 ServiceError with the Allow header set to the methods
+func newMethodNotAllowedError(methods []string) ServiceError {
+	header := http.Header{HEADER_Allow: []string{strings.Join(methods, ", ")}}
+	return NewErrorWithHeader(http.StatusMethodNotAllowed, "405: Method Not Allowed", header)
+}
+
+// newInvalidParametersError returns a 400 ServiceError that lists the problems with the parameters of a request
+func newInvalidParametersError(problems []string) ServiceError {
+	return NewError(http.StatusBadRequest, "400: Invalid parameters: "+strings.Join(problems, "; "))
+}
+
+// newInvalidBodyError returns a 400 ServiceError that lists the problems with the body of a request
+func newInvalidBodyError(problems []string) ServiceError {
+	return NewError(http.StatusBadRequest, "400: Invalid body: "+strings.Join(problems, "; "))
+}
@@ -0,0 +1,212 @@
+package restful
+
+// Copyright 2024 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// StrictHeaderExemptions are the X- headers that are accepted by StrictHeaderParameters without being documented.
+// These are typically added by proxies or clients and not by the caller of the API.
+var StrictHeaderExemptions = []string{
+	"X-Forwarded-For",
+	"X-Forwarded-Host",
+	"X-Forwarded-Port",
+	"X-Forwarded-Prefix",
+	"X-Forwarded-Proto",
+	"X-Real-Ip",
+	"X-Request-Id",
+	HEADER_XHTTPMethodOverride,
+	HEADER_XHTTPMethod,
+}
+
+// StrictQueryParameters (default=false) rejects requests with query parameters that are not documented on the selected Route.
+// A documented parameter with Style deepObject also accepts its keys in brackets, e.g. filter[status].
+// Such request is answered with a ServiceError with status 400 (Bad Request) that lists the unknown names and the closest documented names.
+// Use the RouteBuilder StrictQueryParameters to override this value.
+func (w *WebService) StrictQueryParameters(strict bool) *WebService {
+	w.strictQueryParameters = &strict
+	return w
+}
+
+// StrictHeaderParameters (default=false) rejects requests with X- headers that are not documented on the selected Route,
+// except for the StrictHeaderExemptions. See StrictQueryParameters.
+// Use the RouteBuilder StrictHeaderParameters to override this value.
+func (w *WebService) StrictHeaderParameters(strict bool) *WebService {
+	w.strictHeaderParameters = &strict
+	return w
+}
+
+// StrictQueryParameters rejects requests with undocumented query parameters. Overrides the WebService value.
+func (b *RouteBuilder) StrictQueryParameters(strict bool) *RouteBuilder {
+	b.strictQueryParameters = &strict
+	return b
+}
+
+// StrictHeaderParameters rejects requests with undocumented X- headers. Overrides the WebService value.
+func (b *RouteBuilder) StrictHeaderParameters(strict bool) *RouteBuilder {
+	b.strictHeaderParameters = &strict
+	return b
+}
+
+// strictParametersFor returns whether undocumented query parameters and X- headers must be rejected.
+func strictParametersFor(webService *WebService, route *Route) (query bool, header bool) {
+	if route.strictQueryParameters != nil {
+		query = *route.strictQueryParameters
+	} else if webService.strictQueryParameters != nil {
+		query = *webService.strictQueryParameters
+	}
+	if route.strictHeaderParameters != nil {
+		header = *route.strictHeaderParameters
+	} else if webService.strictHeaderParameters != nil {
+		header = *webService.strictHeaderParameters
+	}
+	return
+}
+
+// rejectingUnknownParameters returns a RouteFunction that checks for undocumented parameters before calling the function.
+func (c *Container) rejectingUnknownParameters(route *Route, query, header bool, function RouteFunction) RouteFunction {
+	return func(req *Request, resp *Response) {
+		// the parameter and header of the version negotiation are not documented per Route
+		versionQuery, versionHeader := "", ""
+		if c.apiVersionNegotiation != nil {
+			versionQuery, versionHeader = c.apiVersionNegotiation.QueryParameter, c.apiVersionNegotiation.Header
+		}
+		problems := []string{}
+		if query {
+			problems = append(problems, unknownQueryParameters(req.Request, route.ParameterDocs, versionQuery)...)
+		}
+		if header {
+			problems = append(problems, unknownHeaderParameters(req.Request, route.ParameterDocs, versionHeader)...)
+		}
+		if len(problems) > 0 {
+			c.serviceErrorHandleFunc(NewError(http.StatusBadRequest, "400: Unknown parameters: "+strings.Join(problems, "; ")), req, resp)
+			return
+		}
+		function(req, resp)
+	}
+}
+
+// unknownQueryParameters returns a problem for each query parameter that is not documented or exempted.
+func unknownQueryParameters(httpRequest *http.Request, parameters []*Parameter, exempted string) []string {
+	documented := []string{}
+	deepObjects := map[string]bool{}
+	for _, each := range parameters {
+		if each.data.Kind == QueryParameterKind {
+			documented = append(documented, each.data.Name)
+			if each.data.Style == StyleDeepObject.String() {
+				deepObjects[each.data.Name] = true
+			}
+		}
+	}
+	names := []string{}
+	for name := range httpRequest.URL.Query() {
+		if containsString(documented, name) || name == exempted {
+			continue
+		}
+		if bracket := strings.Index(name, "["); bracket > 0 {
+			if deepObjects[name[:bracket]] || name == name[:bracket]+"[]" && containsString(documented, name[:bracket]) {
+				continue
+			}
+		}
+		names = append(names, name)
+	}
+	return unknownParameterProblems("query parameter", names, documented, false)
+}
+
+// unknownHeaderParameters returns a problem for each X- header that is not documented or exempted.
+func unknownHeaderParameters(httpRequest *http.Request, parameters []*Parameter, exempted string) []string {
+	documented := []string{}
+	known := map[string]bool{}
+	for _, each := range parameters {
+		if each.data.Kind == HeaderParameterKind {
+			documented = append(documented, each.data.Name)
+			known[http.CanonicalHeaderKey(each.data.Name)] = true
+		}
+	}
+	for _, each := range StrictHeaderExemptions {
+		known[http.CanonicalHeaderKey(each)] = true
+	}
+	if len(exempted) > 0 {
+		known[http.CanonicalHeaderKey(exempted)] = true
+	}
+	names := []string{}
+	for name := range httpRequest.Header {
+		if strings.HasPrefix(strings.ToUpper(name), "X-") && !known[http.CanonicalHeaderKey(name)] {
+			names = append(names, name)
+		}
+	}
+	return unknownParameterProblems("header", names, documented, true)
+}
+
+// unknownParameterProblems returns a problem for each (sorted) name including the closest documented name, if any.
+func unknownParameterProblems(kind string, names, documented []string, ignoreCase bool) []string {
+	sort.Strings(names)
+	problems := []string{}
+	for _, each := range names {
+		problem := fmt.Sprintf("%s %s", kind, each)
+		if closest := closestName(each, documented, ignoreCase); len(closest) > 0 {
+			problem += fmt.Sprintf(" (did you mean %s?)", closest)
+		}
+		problems = append(problems, problem)
+	}
+	return problems
+}
+
+// closestName returns the candidate with the smallest edit distance to the name.
+// Returns an empty string if no candidate is close enough, i.e. needs more edits than half the length of the name (minimum 2).
+func closestName(name string, candidates []string, ignoreCase bool) string {
+	closest := ""
+	maxDistance := len(name) / 2
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+	best := maxDistance + 1
+	for _, each := range candidates {
+		a, b := name, each
+		if ignoreCase {
+			a, b = strings.ToLower(a), strings.ToLower(b)
+		}
+		if distance := levenshtein(a, b); distance < best || distance == best && each < closest {
+			closest, best = each, distance
+		}
+	}
+	return closest
+}
+
+// levenshtein returns the minimum number of single character insertions, deletions or substitutions to change a into b.
+func levenshtein(a, b string) int {
+	source, target := []rune(a), []rune(b)
+	previous := make([]int, len(target)+1)
+	current := make([]int, len(target)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(source); i++ {
+		current[0] = i
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+			current[j] = min3(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(target)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,167 @@
+package restful
+
+import (
+	"io"
+	"net/http"
+	"testing"
+)
+
+func newStrictContainer() *Container {
+	wc := NewContainer()
+	ws := new(WebService).Path("/").StrictQueryParameters(true)
+	ok := func(req *Request, resp *Response) { io.WriteString(resp, "ok") }
+	ws.Route(ws.GET("/issues").
+		Param(ws.QueryParameter("limit", "")).
+		Param(ws.QueryParameter("offset", "")).
+		Param(ws.QueryParameter("sort", "")).
+		Param(ws.QueryParameter("filter", "").Style(StyleDeepObject)).
+		Param(ws.HeaderParameter("X-Trace", "")).
+		StrictHeaderParameters(true).
+		To(ok).Operation("listIssues"))
+	ws.Route(ws.GET("/lenient").StrictQueryParameters(false).To(ok).Operation("lenient"))
+	wc.Add(ws)
+	return wc
+}
+
+// go test -v -test.run TestStrictParameters_Documented ...restful
+func TestStrictParameters_Documented(t *testing.T) {
+	header := http.Header{"X-Trace": {"1"}, "X-Forwarded-For": {"1.2.3.4"}, "Accept-Language": {"nl"}}
+	httpWriter := dispatchRequest(newStrictContainer(), "GET", "/issues?limit=1&offset=2&filter[status]=open&filter[owner][id]=7&sort[]=name", header, "")
+	if 200 != httpWriter.Code {
+		t.Errorf("documented parameters, exempted and non X- headers must be accepted, got %d: %s", httpWriter.Code, httpWriter.Body.String())
+	}
+}
+
+// go test -v -test.run TestStrictParameters_NotCalled ...restful
+func TestStrictParameters_NotCalled(t *testing.T) {
+	httpWriter := dispatchRequest(newStrictContainer(), "GET", "/issues?verbose=true", nil, "")
+	if 400 != httpWriter.Code || "ok" == httpWriter.Body.String() {
+		t.Errorf("the Route function must not be called, got %d: %s", httpWriter.Code, httpWriter.Body.String())
+	}
+}
+
+// go test -v -test.run TestStrictParameters_Suggestion ...restful
+func TestStrictParameters_Suggestion(t *testing.T) {
+	httpWriter := dispatchRequest(newStrictContainer(), "GET", "/issues?limt=10", nil, "")
+	if want := "400: Unknown parameters: query parameter limt (did you mean limit?)"; want != httpWriter.Body.String() {
+		t.Errorf("got %q want %q", httpWriter.Body.String(), want)
+	}
+}
+
+// go test -v -test.run TestStrictParameters_SortedProblems ...restful
+func TestStrictParameters_SortedProblems(t *testing.T) {
+	httpWriter := dispatchRequest(newStrictContainer(), "GET", "/issues?verbose=true&offst=1", nil, "")
+	if want := "400: Unknown parameters: query parameter offst (did you mean offset?); query parameter verbose"; want != httpWriter.Body.String() {
+		t.Errorf("got %q want %q", httpWriter.Body.String(), want)
+	}
+}
+
+// go test -v -test.run TestStrictParameters_Brackets ...restful
+func TestStrictParameters_Brackets(t *testing.T) {
+	wc := newStrictContainer()
+	httpWriter := dispatchRequest(wc, "GET", "/issues?limit[a]=1", nil, "")
+	if want := "400: Unknown parameters: query parameter limit[a] (did you mean limit?)"; want != httpWriter.Body.String() {
+		t.Errorf("keys in brackets are only accepted for a deepObject, got %q want %q", httpWriter.Body.String(), want)
+	}
+	if httpWriter := dispatchRequest(wc, "GET", "/issues?sort[]=a&sort[]=b", nil, ""); 200 != httpWriter.Code {
+		t.Error("[] must be accepted for a documented parameter, got", httpWriter.Body.String())
+	}
+	if httpWriter := dispatchRequest(wc, "GET", "/issues?[]=a", nil, ""); 400 != httpWriter.Code {
+		t.Error("400 expected for a key without name, got", httpWriter.Code)
+	}
+}
+
+// go test -v -test.run TestStrictParameters_Headers ...restful
+func TestStrictParameters_Headers(t *testing.T) {
+	httpWriter := dispatchRequest(newStrictContainer(), "GET", "/issues", http.Header{"X-Tarce": {"1"}, "X-Custom-Thing": {"2"}}, "")
+	if want := "400: Unknown parameters: header X-Custom-Thing; header X-Tarce (did you mean X-Trace?)"; want != httpWriter.Body.String() {
+		t.Errorf("got %q want %q", httpWriter.Body.String(), want)
+	}
+}
+
+// go test -v -test.run TestStrictParameters_HeaderNamesIgnoreCase ...restful
+func TestStrictParameters_HeaderNamesIgnoreCase(t *testing.T) {
+	wc := NewContainer()
+	ws := new(WebService).Path("/issues").StrictHeaderParameters(true)
+	ws.Route(ws.GET("").Param(ws.HeaderParameter("x-trace-id", "")).To(dummy).Operation("listIssues"))
+	wc.Add(ws)
+	if httpWriter := dispatchRequest(wc, "GET", "/issues", http.Header{"X-Trace-Id": {"1"}}, ""); 200 != httpWriter.Code {
+		t.Error("header names must be compared ignoring case, got", httpWriter.Body.String())
+	}
+	httpWriter := dispatchRequest(wc, "GET", "/issues", http.Header{"X-Trace-Ix": {"1"}}, "")
+	if want := "400: Unknown parameters: header X-Trace-Ix (did you mean x-trace-id?)"; want != httpWriter.Body.String() {
+		t.Errorf("got %q want %q", httpWriter.Body.String(), want)
+	}
+}
+
+// go test -v -test.run TestStrictParameters_Exemptions ...restful
+func TestStrictParameters_Exemptions(t *testing.T) {
+	wc := NewContainer()
+	ws := new(WebService).Path("/issues").StrictHeaderParameters(true)
+	ws.Route(ws.GET("").To(dummy).Operation("listIssues"))
+	wc.Add(ws)
+	header := http.Header{}
+	for _, each := range StrictHeaderExemptions {
+		header.Set(each, "1")
+	}
+	if httpWriter := dispatchRequest(wc, "GET", "/issues", header, ""); 200 != httpWriter.Code {
+		t.Error("exempted headers must be accepted, got", httpWriter.Body.String())
+	}
+}
+
+// go test -v -test.run TestStrictParameters_Overrides ...restful
+func TestStrictParameters_Overrides(t *testing.T) {
+	if httpWriter := dispatchRequest(newStrictContainer(), "GET", "/lenient?anything=1", nil, ""); 200 != httpWriter.Code {
+		t.Error("the Route overrides the WebService, got", httpWriter.Body.String())
+	}
+	wc := NewContainer()
+	ws := new(WebService).Path("/issues")
+	ws.Route(ws.GET("").To(dummy).Operation("listIssues"))
+	ws.Route(ws.POST("").StrictQueryParameters(true).To(dummy).Operation("createIssue"))
+	wc.Add(ws)
+	if httpWriter := dispatchRequest(wc, "GET", "/issues?anything=1", http.Header{"X-Anything": {"1"}}, ""); 200 != httpWriter.Code {
+		t.Error("not strict by default, got", httpWriter.Body.String())
+	}
+	if httpWriter := dispatchRequest(wc, "POST", "/issues?anything=1", nil, ""); 400 != httpWriter.Code {
+		t.Error("strict for the Route, got", httpWriter.Code)
+	}
+}
+
+// go test -v -test.run TestClosestName ...restful
+func TestClosestName(t *testing.T) {
+	for _, each := range []struct {
+		name, want string
+	}{
+		{"limt", "limit"},
+		{"lmt", "limit"},
+		{"lt", ""},
+		{"verbose", ""},
+		{"sorted", "sort"},
+	} {
+		if got := closestName(each.name, []string{"limit", "offset", "sort"}, false); got != each.want {
+			t.Errorf("%s: got %q want %q", each.name, got, each.want)
+		}
+	}
+	if got := closestName("ab", []string{"bb", "aa"}, false); got != "aa" {
+		t.Errorf("the first name in order must win a tie, got %q", got)
+	}
+}
+
+// go test -v -test.run TestLevenshtein ...restful
+func TestLevenshtein(t *testing.T) {
+	for _, each := range []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"limit", "limit", 0},
+		{"limt", "limit", 1},
+		{"kitten", "sitting", 3},
+		{"", "abc", 3},
+		{"été", "ete", 2},
+	} {
+		if got := levenshtein(each.a, each.b); got != each.want {
+			t.Errorf("%s,%s: got %v want %v", each.a, each.b, got, each.want)
+		}
+	}
+}
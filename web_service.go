@@ -4,7 +4,9 @@ import (
 	"errors"
 	"os"
 	"reflect"
+	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/emicklei/go-restful/v3/log"
 )
@@ -16,7 +18,8 @@ import (
 // WebService holds a collection of Route values that bind a Http Method + URL Path to a function.
 type WebService struct {
 	rootPath       string
-	pathExpr       *pathExpression // cached compilation of rootPath as RegExp
+	pathExpr       *pathExpression   // cached compilation of rootPath as RegExp
+	hosts          []*hostExpression // cached compilation of host patterns ; empty means any host
 	routes         []Route
 	produces       []string
 	consumes       []string
@@ -29,8 +32,18 @@ type WebService struct {
 
 	dynamicRoutes bool
 
+	// overrides the container.autoHeadEnabled
+	autoHeadEnabled *bool
+
+	// reject undocumented query parameters and X- headers ; overridden by a Route
+	strictQueryParameters  *bool
+	strictHeaderParameters *bool
+
 	// protects 'routes' if dynamic routes are enabled
 	routesLock sync.RWMutex
+
+	// incremented each time the routes change ; used by routers that precompile routes
+	routesVersion uint32
 }
 
 func (w *WebService) SetDynamicRoutes(enable bool) {
@@ -67,6 +80,7 @@ func (w *WebService) compilePathExpression() {
 }
 
 // ApiVersion sets the API version for documentation purposes.
+// It is also used to select between WebServices with the same root path, see Container.ApiVersionNegotiation.
 func (w *WebService) ApiVersion(apiVersion string) *WebService {
 	w.apiVersion = apiVersion
 	return w
@@ -86,11 +100,97 @@ func (w *WebService) Path(root string) *WebService {
 	return w
 }
 
+// Host specifies one or more host patterns, e.g. api.example.com or {tenant}.example.com.
+// Only requests for a matching host (port excluded, case-insensitive) are dispatched to its Routes.
+// Values of host parameters are available using Request.PathParameter.
+// If no host is specified then the WebService handles requests for any host.
+func (w *WebService) Host(patterns ...string) *WebService {
+	w.hosts = []*hostExpression{}
+	for _, each := range patterns {
+		compiled, err := newHostExpression(each)
+		if err != nil {
+			log.Printf("invalid host:%s because:%v", each, err)
+			os.Exit(1)
+		}
+		w.hosts = append(w.hosts, compiled)
+	}
+	return w
+}
+
+// Hosts returns the host patterns of this WebService. Empty if it handles requests for any host.
+func (w *WebService) Hosts() []string {
+	patterns := make([]string, 0, len(w.hosts))
+	for _, each := range w.hosts {
+		patterns = append(patterns, each.Source)
+	}
+	return patterns
+}
+
+// matchesHost returns whether the host (without port) matches any of its host patterns.
+func (w *WebService) matchesHost(host string) bool {
+	_, ok := w.hostParameters(host)
+	return ok
+}
+
+// hostParameters returns the parameter values from the first matching host pattern.
+func (w *WebService) hostParameters(host string) (map[string]string, bool) {
+	if len(w.hosts) == 0 {
+		return map[string]string{}, true
+	}
+	host = hostWithoutPort(host)
+	for _, each := range w.hosts {
+		if params, ok := each.extractParameters(host); ok {
+			return params, true
+		}
+	}
+	return nil, false
+}
+
+// hasSameRoot returns whether the other WebService has the same root path, host patterns and API version.
+func (w *WebService) hasSameRoot(other *WebService) bool {
+	return w.hasSameRootAndHosts(other) && sameApiVersion(w.apiVersion, other.apiVersion)
+}
+
+// hasSameRootAndHosts returns whether the other WebService has the same root path and host patterns.
+func (w *WebService) hasSameRootAndHosts(other *WebService) bool {
+	if w.rootPath != other.rootPath {
+		return false
+	}
+	hosts, otherHosts := w.Hosts(), other.Hosts()
+	if len(hosts) != len(otherHosts) {
+		return false
+	}
+	for i := range hosts {
+		if hosts[i] != otherHosts[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// hostsOverlap returns whether both WebServices can handle requests for the same host.
+func (w *WebService) hostsOverlap(other *WebService) bool {
+	if len(w.hosts) == 0 || len(other.hosts) == 0 {
+		return true
+	}
+	for _, each := range w.hosts {
+		for _, otherHost := range other.hosts {
+			if strings.EqualFold(each.Source, otherHost.Source) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // Param adds a PathParameter to document parameters used in the root path.
+// Use a HostParameter to document parameters used in a host pattern.
+// If the root path declares a typed parameter, e.g. {id:int}, then its DataType and DataFormat are set accordingly.
 func (w *WebService) Param(parameter *Parameter) *WebService {
 	if w.pathParameters == nil {
 		w.pathParameters = []*Parameter{}
 	}
+	applyPathParameterConstraints(w.rootPath, []*Parameter{parameter})
 	w.pathParameters = append(w.pathParameters, parameter)
 	return w
 }
@@ -123,6 +223,49 @@ func QueryParameter(name, description string) *Parameter {
 	return p
 }
 
+// HostParameter creates a new Parameter of kind Host for documentation purposes.
+// It is initialized as required with string as its DataType.
+func (w *WebService) HostParameter(name, description string) *Parameter {
+	return HostParameter(name, description)
+}
+
+// HostParameter creates a new Parameter of kind Host for documentation purposes.
+// It is initialized as required with string as its DataType.
+func HostParameter(name, description string) *Parameter {
+	p := &Parameter{&ParameterData{Name: name, Description: description, Required: true, DataType: "string"}}
+	p.beHost()
+	return p
+}
+
+// MatrixParameter creates a new Parameter of kind Matrix for documentation purposes.
+// It is initialized as not required with string as its DataType.
+func (w *WebService) MatrixParameter(name, description string) *Parameter {
+	return MatrixParameter(name, description)
+}
+
+// MatrixParameter creates a new Parameter of kind Matrix for documentation purposes.
+// It is initialized as not required with string as its DataType.
+// Matrix parameters are only recognized if the Container has EnableMatrixParameters.
+func MatrixParameter(name, description string) *Parameter {
+	p := &Parameter{&ParameterData{Name: name, Description: description, Required: false, DataType: "string"}}
+	p.beMatrix()
+	return p
+}
+
+// CookieParameter creates a new Parameter of kind Cookie for documentation purposes.
+// It is initialized as not required with string as its DataType.
+func (w *WebService) CookieParameter(name, description string) *Parameter {
+	return CookieParameter(name, description)
+}
+
+// CookieParameter creates a new Parameter of kind Cookie for documentation purposes.
+// It is initialized as not required with string as its DataType.
+func CookieParameter(name, description string) *Parameter {
+	p := &Parameter{&ParameterData{Name: name, Description: description, Required: false, DataType: "string"}}
+	p.beCookie()
+	return p
+}
+
 // BodyParameter creates a new Parameter of kind Body for documentation purposes.
 // It is initialized as required without a DataType.
 func (w *WebService) BodyParameter(name, description string) *Parameter {
@@ -181,8 +324,11 @@ func MultiPartFormParameter(name, description string) *Parameter {
 func (w *WebService) Route(builder *RouteBuilder) *WebService {
 	w.routesLock.Lock()
 	defer w.routesLock.Unlock()
+	// the group wins from the WebService
+	builder.applyGroup()
 	builder.copyDefaults(w.produces, w.consumes)
 	w.routes = append(w.routes, builder.Build())
+	atomic.AddUint32(&w.routesVersion, 1)
 	return w
 }
 
@@ -201,9 +347,39 @@ func (w *WebService) RemoveRoute(path, method string) error {
         newRoutes = append(newRoutes, route)
     }
     w.routes = newRoutes
+    atomic.AddUint32(&w.routesVersion, 1)
     return nil
 }
 
+// RouteConflicts returns the conflicts between its Routes, e.g. GET /users/{id} and GET /users/{name}.
+func (w *WebService) RouteConflicts() []RouteConflict {
+	return routeConflictsAmong(w.Routes())
+}
+
+// CheckRouteConflicts returns a RouteConflictError if any of its Routes conflict.
+// Use this in a test or at startup to prevent registering ambiguous Routes.
+func (w *WebService) CheckRouteConflicts() error {
+	if conflicts := w.RouteConflicts(); len(conflicts) > 0 {
+		return RouteConflictError{Conflicts: conflicts}
+	}
+	return nil
+}
+
+// routeConflictsWithService returns the conflicts between its Routes and those of another WebService.
+func (w *WebService) routeConflictsWithService(other *WebService) (conflicts []RouteConflict) {
+	if !w.hostsOverlap(other) {
+		return
+	}
+	if len(w.apiVersion) > 0 && len(other.apiVersion) > 0 && !sameApiVersion(w.apiVersion, other.apiVersion) {
+		return
+	}
+	otherRoutes := other.Routes()
+	for _, each := range w.Routes() {
+		conflicts = append(conflicts, routeConflictsWith(each, otherRoutes)...)
+	}
+	return
+}
+
 // Method creates a new RouteBuilder and initialize its http method
 func (w *WebService) Method(httpMethod string) *RouteBuilder {
 	return new(RouteBuilder).typeNameHandler(w.typeNameHandleFunc).servicePath(w.rootPath).Method(httpMethod)
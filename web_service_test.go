@@ -37,6 +37,9 @@ func TestWebService_CanCreateParameterKinds(t *testing.T) {
 	if ws.QueryParameter("q", "q").Kind() != QueryParameterKind {
 		t.Error("query parameter expected")
 	}
+	if ws.CookieParameter("c", "c").Kind() != CookieParameterKind {
+		t.Error("cookie parameter expected")
+	}
 }
 
 func TestCapturePanic(t *testing.T) {